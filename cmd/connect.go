@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/prathoss/goftp/pkg"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	// passwordEnv is environment variable with password used when the flag is not set
+	passwordEnv = "GOFTP_PASSWORD"
+	// vaultPassphraseEnv unlocks the vault without prompting
+	vaultPassphraseEnv = "GOFTP_VAULT_PASSPHRASE"
+)
+
+var (
+	password string
+	conflict string
+)
+
+func addPasswordFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&password, "password", "", fmt.Sprintf("password, read from %s, the remembered one or stdin when not set", passwordEnv))
+}
+
+func addConflictFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&conflict,
+		"conflict",
+		"",
+		fmt.Sprintf(
+			"what to do with existing files, one of %s, default is the one of the connection, ask is not possible and overwrites",
+			strings.Join(pkg.MapSlice(pkg.ConflictPolicies, pkg.ConflictPolicy.String), ", "),
+		),
+	)
+}
+
+// savedConnection finds saved connection named target or matching target in form [user@]server[:port]
+func savedConnection(target string) (pkg.ServerConf, error) {
+	cfg, err := pkg.GetConfig()
+	if err != nil {
+		return pkg.ServerConf{}, err
+	}
+	for _, conf := range cfg.Servers {
+		if conf.Name != "" && conf.Name == target {
+			return conf, nil
+		}
+	}
+	user, host := "", target
+	if i := strings.LastIndex(target, "@"); i >= 0 {
+		user, host = target[:i], target[i+1:]
+	}
+	port := 0
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		port, err = strconv.Atoi(host[i+1:])
+		if err != nil {
+			return pkg.ServerConf{}, usageErrorf("invalid port in %q", target)
+		}
+		host = host[:i]
+	}
+	var found []pkg.ServerConf
+	for _, conf := range cfg.Servers {
+		if conf.Server == host && (user == "" || conf.User == user) && (port == 0 || conf.Port == port) {
+			found = append(found, conf)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return pkg.ServerConf{}, usageErrorf("no saved connection matches %q", target)
+	case 1:
+		return found[0], nil
+	default:
+		return pkg.ServerConf{}, usageErrorf("%d saved connections match %q, specify user or port", len(found), target)
+	}
+}
+
+// readPassword returns password from the flag, environment, password store or stdin, terminal does not echo it
+func readPassword(conf pkg.ServerConf) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	if value, ok := os.LookupEnv(passwordEnv); ok {
+		return value, nil
+	}
+	passwd, err := rememberedPassword(conf)
+	if !errors.Is(err, pkg.ErrNoPassword) {
+		return passwd, err
+	}
+	return prompt("Password: ")
+}
+
+func rememberedPassword(conf pkg.ServerConf) (string, error) {
+	switch conf.PasswordStore {
+	case pkg.PasswordStoreSecretService:
+		return pkg.SecretService{}.Password(conf)
+	case pkg.PasswordStoreVault:
+		passphrase, ok := os.LookupEnv(vaultPassphraseEnv)
+		if !ok {
+			var err error
+			if passphrase, err = prompt("Vault passphrase: "); err != nil {
+				return "", err
+			}
+		}
+		vault, err := pkg.OpenVault(passphrase)
+		if err != nil {
+			return "", err
+		}
+		return vault.Password(conf)
+	default:
+		return "", pkg.ErrNoPassword
+	}
+}
+
+// prompt reads secret line from stdin, terminal gets the prompt and does not echo the input
+func prompt(text string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, text)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(value), err
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// stdin is shared, so lines read ahead by buffering are not lost between prompts
+var stdin = bufio.NewReader(os.Stdin)
+
+// remoteConnection is dialed saved connection, transfers are spread over pool of connections
+type remoteConnection struct {
+	*pkg.Pool
+	conf pkg.ServerConf
+}
+
+// connect dials saved connection matching target
+func connect(target string) (remoteConnection, error) {
+	conf, err := savedConnection(target)
+	if err != nil {
+		return remoteConnection{}, err
+	}
+	passwd, err := readPassword(conf)
+	if err != nil {
+		return remoteConnection{}, err
+	}
+	// fail early on wrong credentials, the connection is reused by the pool
+	remote, err := pkg.Dial(conf, passwd)
+	var unknownKey *pkg.UnknownHostKeyError
+	if errors.As(err, &unknownKey) {
+		return remoteConnection{}, connectionError(fmt.Errorf("%w, verify it and trust it by connecting in the TUI", err))
+	}
+	if err != nil {
+		return remoteConnection{}, connectionError(err)
+	}
+	first := make(chan pkg.RemoteFS, 1)
+	first <- remote
+	pool := pkg.NewPool(func() (pkg.RemoteFS, error) {
+		select {
+		case fsys := <-first:
+			return fsys, nil
+		default:
+			return pkg.Dial(conf, passwd)
+		}
+	}, conf.TransferConnections())
+	return remoteConnection{Pool: pool, conf: conf}, nil
+}
+
+// transferOptions uses policy from the flag or the connection
+func (r remoteConnection) transferOptions() (pkg.TransferOptions, error) {
+	policy, err := pkg.ParseConflictPolicy(conflict)
+	if err != nil {
+		return pkg.TransferOptions{}, usageError(err)
+	}
+	if conflict == "" {
+		policy = r.conf.Conflict
+	}
+	if policy == pkg.ConflictAsk {
+		policy = pkg.ConflictOverwrite
+	}
+	return pkg.TransferOptions{Conflict: policy}, nil
+}
+
+// parseRemotePaths splits arguments in form <connection>:<absolute path>, all must use the same connection
+func parseRemotePaths(args []string) (string, []string, error) {
+	var target string
+	locations := make([]string, 0, len(args))
+	for _, arg := range args {
+		i := strings.Index(arg, ":/")
+		if i < 0 {
+			return "", nil, usageErrorf("%q is not in form <connection>:<absolute path>", arg)
+		}
+		if target != "" && arg[:i] != target {
+			return "", nil, usageErrorf("all remote paths must use the same connection")
+		}
+		target = arg[:i]
+		locations = append(locations, arg[i+1:])
+	}
+	return target, locations, nil
+}
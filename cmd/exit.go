@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// exit codes of the subcommands, other failures exit with 1
+const (
+	exitUsage      = 2
+	exitConnection = 3
+)
+
+// exitError sets exit code of the program
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e exitError) Unwrap() error {
+	return e.err
+}
+
+func usageError(err error) error {
+	return exitError{code: exitUsage, err: err}
+}
+
+func usageErrorf(format string, a ...interface{}) error {
+	return usageError(fmt.Errorf(format, a...))
+}
+
+func connectionError(err error) error {
+	return exitError{code: exitConnection, err: err}
+}
+
+// usageArgs marks errors of args validation as usage errors
+func usageArgs(args cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, a []string) error {
+		if err := args(cmd, a); err != nil {
+			return usageError(err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/prathoss/goftp/pkg"
+	"github.com/spf13/cobra"
+)
+
+var getCmd = &cobra.Command{
+	Use:          "get <connection>:<remote path>... <local path>",
+	Short:        "Download files from the server",
+	Example:      "goftp get user@example.com:/var/www/index.html ./ -r",
+	Args:         usageArgs(cobra.MinimumNArgs(2)),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, sources, err := parseRemotePaths(args[:len(args)-1])
+		if err != nil {
+			return err
+		}
+		destination, err := absolutePaths(args[len(args)-1:])
+		if err != nil {
+			return err
+		}
+		remote, err := connect(target)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+		opts, err := remote.transferOptions()
+		if err != nil {
+			return err
+		}
+		return copyPaths(remote.Pool, sources, pkg.LocalFS{}, destination[0], opts)
+	},
+}
+
+func init() {
+	getCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "download directories with their content")
+	addConflictFlag(getCmd)
+	addPasswordFlag(getCmd)
+	rootCmd.AddCommand(getCmd)
+}
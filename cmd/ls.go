@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prathoss/goftp/types"
+	"github.com/spf13/cobra"
+)
+
+var lsLong bool
+
+var lsCmd = &cobra.Command{
+	Use:          "ls <connection>:<remote path>",
+	Short:        "List remote directory",
+	Args:         usageArgs(cobra.ExactArgs(1)),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, locations, err := parseRemotePaths(args)
+		if err != nil {
+			return err
+		}
+		remote, err := connect(target)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+		entry, err := remote.Stat(locations[0])
+		if err != nil {
+			return err
+		}
+		entries := []types.Entry{entry}
+		if entry.Type == types.TypeDirectory {
+			if entries, err = remote.List(locations[0]); err != nil {
+				return err
+			}
+		}
+		for _, entry := range entries {
+			if !lsLong {
+				fmt.Fprintln(cmd.OutOrStdout(), entry.Name)
+				continue
+			}
+			modified := "-"
+			if !entry.ModTime.IsZero() {
+				modified = entry.ModTime.Format(time.RFC3339)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d\t%s\t%s\n", entry.TypeString(), entry.Size, modified, entry.Name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	lsCmd.Flags().BoolVarP(&lsLong, "long", "l", false, "print type, size in bytes and modification time")
+	addPasswordFlag(lsCmd)
+	rootCmd.AddCommand(lsCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/prathoss/goftp/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mirrorDownload bool
+	mirrorDelete   bool
+	mirrorDryRun   bool
+)
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror <connection> <local dir> <remote dir>",
+	Short: "Make remote directory same as the local one, or the other way with --download",
+	Long: `Make remote directory same as the local one, or the other way with --download.
+Files are transferred when missing, their size differs or the source is newer.
+Connection is name of a saved one or [user@]server[:port] matching it.`,
+	Args:         usageArgs(cobra.ExactArgs(3)),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		localDir, err := filepath.Abs(args[1])
+		if err != nil {
+			return err
+		}
+		remote, err := connect(args[0])
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+
+		var plan pkg.MirrorPlan
+		opts := pkg.MirrorOptions{DeleteExtraneous: mirrorDelete}
+		if mirrorDownload {
+			plan, err = pkg.PlanMirror(remote.Pool, args[2], pkg.LocalFS{}, localDir, opts)
+		} else {
+			plan, err = pkg.PlanMirror(pkg.LocalFS{}, localDir, remote.Pool, args[2], opts)
+		}
+		if err != nil {
+			return err
+		}
+		printStep := func(step pkg.MirrorStep) {
+			fmt.Fprintf(cmd.OutOrStdout(), "%-6s %s\n", step.Action, step.Path)
+		}
+		if mirrorDryRun {
+			for _, step := range plan.Steps {
+				printStep(step)
+			}
+			return nil
+		}
+		for _, step := range plan.Steps {
+			if step.Action == pkg.MirrorSkip {
+				printStep(step)
+			}
+		}
+		return plan.Execute(context.Background(), printStep)
+	},
+}
+
+func init() {
+	mirrorCmd.Flags().BoolVar(&mirrorDownload, "download", false, "mirror the remote directory into the local one")
+	mirrorCmd.Flags().BoolVar(&mirrorDelete, "delete", false, "delete files which are not in the source directory")
+	mirrorCmd.Flags().BoolVar(&mirrorDryRun, "dry-run", false, "only print the plan")
+	addPasswordFlag(mirrorCmd)
+	rootCmd.AddCommand(mirrorCmd)
+}
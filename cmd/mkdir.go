@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/prathoss/goftp/pkg"
+	"github.com/spf13/cobra"
+)
+
+var mkdirParents bool
+
+var mkdirCmd = &cobra.Command{
+	Use:          "mkdir <connection>:<remote path>...",
+	Short:        "Create remote directories",
+	Args:         usageArgs(cobra.MinimumNArgs(1)),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, locations, err := parseRemotePaths(args)
+		if err != nil {
+			return err
+		}
+		remote, err := connect(target)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+		for _, location := range locations {
+			if mkdirParents {
+				err = pkg.MkdirAll(remote.Pool, location)
+			} else {
+				err = remote.Mkdir(location)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	mkdirCmd.Flags().BoolVarP(&mkdirParents, "parents", "p", false, "create missing parents, existing directories are not an error")
+	addPasswordFlag(mkdirCmd)
+	rootCmd.AddCommand(mkdirCmd)
+}
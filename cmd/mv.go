@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/prathoss/goftp/types"
+	"github.com/spf13/cobra"
+)
+
+var mvCmd = &cobra.Command{
+	Use:          "mv <connection>:<remote path> <remote path>",
+	Short:        "Move or rename remote file, into the target when it is a directory",
+	Long:         "Move or rename remote file, into the target when it is a directory. The target may be given with the connection as well.",
+	Example:      "goftp mv user@example.com:/www/old.html /www/new.html",
+	Args:         usageArgs(cobra.ExactArgs(2)),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, locations, err := parseRemotePaths(args[:1])
+		if err != nil {
+			return err
+		}
+		from, to := locations[0], args[1]
+		if !strings.HasPrefix(to, "/") {
+			// target with connection, parsing checks it is the same one
+			if _, locations, err = parseRemotePaths(args); err != nil {
+				return err
+			}
+			to = locations[1]
+		}
+		remote, err := connect(target)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+		entry, err := remote.Stat(to)
+		switch {
+		case err == nil && entry.Type == types.TypeDirectory:
+			to = path.Join(to, path.Base(from))
+		case err != nil && !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+		return remote.Rename(from, to)
+	},
+}
+
+func init() {
+	addPasswordFlag(mvCmd)
+	rootCmd.AddCommand(mvCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/prathoss/goftp/pkg"
+	"github.com/spf13/cobra"
+)
+
+var putCmd = &cobra.Command{
+	Use:          "put <local path>... <connection>:<remote path>",
+	Short:        "Upload files to the server",
+	Example:      "goftp put ./dist user@example.com:/var/www -r",
+	Args:         usageArgs(cobra.MinimumNArgs(2)),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, destination, err := parseRemotePaths(args[len(args)-1:])
+		if err != nil {
+			return err
+		}
+		sources, err := absolutePaths(args[:len(args)-1])
+		if err != nil {
+			return err
+		}
+		remote, err := connect(target)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+		opts, err := remote.transferOptions()
+		if err != nil {
+			return err
+		}
+		return copyPaths(pkg.LocalFS{}, sources, remote.Pool, destination[0], opts)
+	},
+}
+
+func init() {
+	putCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "upload directories with their content")
+	addConflictFlag(putCmd)
+	addPasswordFlag(putCmd)
+	rootCmd.AddCommand(putCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"path"
+
+	"github.com/prathoss/goftp/pkg"
+	"github.com/prathoss/goftp/types"
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:          "rm <connection>:<remote path>...",
+	Short:        "Remove remote files",
+	Args:         usageArgs(cobra.MinimumNArgs(1)),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, locations, err := parseRemotePaths(args)
+		if err != nil {
+			return err
+		}
+		remote, err := connect(target)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+		for _, location := range locations {
+			entry, err := remote.Stat(location)
+			if err != nil {
+				return err
+			}
+			if entry.Type == types.TypeDirectory && recursive {
+				err = pkg.RemoveAll(remote.Pool, path.Dir(location), []types.Entry{entry})
+			} else {
+				err = remote.Remove(location)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rmCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "remove directories with their content")
+	addPasswordFlag(rmCmd)
+	rootCmd.AddCommand(rmCmd)
+}
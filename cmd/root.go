@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/prathoss/goftp/pkg"
@@ -11,11 +13,28 @@ import (
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "goftp",
+	Use:   "goftp [url | saved connection]",
 	Short: "A ftp TUI client",
+	Long: `A ftp TUI client, subcommands work without the TUI and use saved connections.
+
+The TUI connects directly to url in form scheme://[user[:password]@]server[:port][/path]
+with scheme ftp, ftps or sftp, or to saved connection given by name or [user@]server[:port].
+Only credentials which are missing are asked for.
+
+Subcommands exit with 1 when the operation fails, 2 on invalid usage
+and 3 when connecting to the server fails.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
+	Args:         usageArgs(cobra.MaximumNArgs(1)),
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			model, err := initConnection(args[0])
+			if err != nil {
+				return err
+			}
+			return tea.NewProgram(model).Start()
+		}
 		cfg, err := pkg.GetConfig()
 		if err != nil {
 			return err
@@ -29,16 +48,53 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// initConnection opens url or saved connection, url of saved connection uses its settings
+func initConnection(target string) (tea.Model, error) {
+	if !strings.Contains(target, "://") {
+		conf, err := savedConnection(target)
+		if err != nil {
+			return nil, err
+		}
+		model, _ := screens.InitConnection(conf, "", "")
+		return model, nil
+	}
+	conf, passwd, location, err := pkg.ParseServerURL(target)
+	if err != nil {
+		return nil, usageError(err)
+	}
+	cfg, err := pkg.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, saved := range cfg.Servers {
+		if saved.SameServer(conf) {
+			conf = saved
+			break
+		}
+	}
+	model, _ := screens.InitConnection(conf, passwd, location)
+	return model, nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	if err == nil {
+		return
 	}
+	var exit exitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.code)
+	}
+	os.Exit(1)
 }
 
 func init() {
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError(err)
+	})
+
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+
+	"github.com/prathoss/goftp/pkg"
+	"github.com/prathoss/goftp/types"
+)
+
+var recursive bool
+
+// copyPaths copies sources to target like cp does, into the target when it is a directory
+func copyPaths(source pkg.RemoteFS, sourcePaths []string, destination pkg.RemoteFS, target string, opts pkg.TransferOptions) error {
+	targetEntry, err := destination.Stat(target)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	targetIsDir := err == nil && targetEntry.Type == types.TypeDirectory
+	if len(sourcePaths) > 1 && !targetIsDir {
+		return usageErrorf("%s is not a directory", target)
+	}
+	for _, sourcePath := range sourcePaths {
+		entry, err := source.Stat(sourcePath)
+		if err != nil {
+			return err
+		}
+		if entry.Type == types.TypeDirectory && !recursive {
+			return fmt.Errorf("%s is a directory, use -r to copy it", sourcePath)
+		}
+		destinationPath := target
+		if targetIsDir {
+			destinationPath = path.Join(target, entry.Name)
+		}
+		if err := pkg.CopyTo(context.Background(), source, sourcePath, destination, destinationPath, opts, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func absolutePaths(paths []string) ([]string, error) {
+	absolute := make([]string, 0, len(paths))
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		absolute = append(absolute, abs)
+	}
+	return absolute, nil
+}
@@ -0,0 +1,78 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/prathoss/goftp/pkg"
+	"github.com/prathoss/goftp/types"
+)
+
+// Column is metadata shown next to entry name
+type Column string
+
+const (
+	ColumnSize        Column = "size"
+	ColumnModTime     Column = "modified"
+	ColumnPermissions Column = "permissions"
+	ColumnOwner       Column = "owner"
+)
+
+// Columns lists all columns in the order they are shown in detailed view
+var Columns = []Column{ColumnSize, ColumnModTime, ColumnPermissions, ColumnOwner}
+
+// DefaultColumns are shown when none are configured
+var DefaultColumns = []Column{ColumnSize}
+
+const cModTimeFormat = "2006-01-02 15:04"
+
+func ParseColumns(names []string) ([]Column, error) {
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		column := Column(name)
+		switch column {
+		case ColumnSize, ColumnModTime, ColumnPermissions, ColumnOwner:
+			columns = append(columns, column)
+		default:
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	return columns, nil
+}
+
+func (c Column) width() int {
+	switch c {
+	case ColumnSize:
+		return flSizeWidth
+	case ColumnModTime:
+		return len(cModTimeFormat)
+	case ColumnPermissions:
+		return len("drwxr-xr-x")
+	default:
+		return 15
+	}
+}
+
+// render returns the value padded to the column width
+func (c Column) render(entry types.Entry) string {
+	var value string
+	switch c {
+	case ColumnSize:
+		value = pkg.PrettyPrintSize(entry.Size)
+	case ColumnModTime:
+		if !entry.ModTime.IsZero() {
+			value = entry.ModTime.Local().Format(cModTimeFormat)
+		}
+	case ColumnPermissions:
+		value = entry.Permissions()
+	case ColumnOwner:
+		if entry.Owner != "" || entry.Group != "" {
+			value = fmt.Sprintf("%s:%s", entry.Owner, entry.Group)
+		}
+	}
+	style := lipgloss.NewStyle().Width(c.width())
+	if c == ColumnSize {
+		style = style.Align(lipgloss.Right)
+	}
+	return style.Render(pkg.Ellipsize(value, c.width()))
+}
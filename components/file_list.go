@@ -1,87 +1,187 @@
 package components
 
 import (
-	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path"
-	"sort"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/prathoss/goftp/pkg"
 	"github.com/prathoss/goftp/types"
 )
 
-type FileListModelBuilder struct {
-	name       string
-	location   string
-	listFn     listFn
-	transferFn transferFn
-	deleteFn   deleteFn
+type FileListModel struct {
+	name     string
+	location string
+	// entries are the ones matching filter, all holds every entry of the location
+	entries  []types.Entry
+	all      []types.Entry
+	cursor   int
+	selected map[string]struct{}
+	// rangeAnchor is the entry range selection started on and rangeBase the selection before it,
+	// the range continues while the cursor stays on rangeEnd, the entry the range ended on
+	rangeAnchor string
+	rangeEnd    string
+	rangeBase   map[string]struct{}
+	fs          pkg.RemoteFS
+	// transferFs is used for transfers, so they do not block browsing
+	transferFs   pkg.RemoteFS
+	topItemIndex int
+	itemsInVew   int
+	// width of the whole list including border
+	width   int
+	columns []Column
+	order   pkg.SortOrder
+	filter  pkg.Filter
+	// searching shows all entries, the filter only tells where NextMatch and PreviousMatch move the cursor
+	searching bool
 }
 
-func InitFileListModelBuilder(name, location string, listFn listFn) *FileListModelBuilder {
-	return &FileListModelBuilder{
-		name:     name,
-		location: location,
-		listFn:   listFn,
+const (
+	// flDefaultItems and flDefaultWidth are used until the size is set
+	flDefaultItems = 10
+	flDefaultWidth = 60
+	// flChromeHeight is height of lines around items, the location, border and counter
+	flChromeHeight = 4
+	// flChromeWidth is width of the border and padding
+	flChromeWidth = 4
+	flSizeWidth   = 12
+	// flMinNameWidth is kept for names, columns which do not fit are hidden
+	flMinNameWidth = 12
+	flColumnGap    = 2
+)
+
+func InitFileListModel(name, location string, fs pkg.RemoteFS) (FileListModel, error) {
+	flm := FileListModel{
+		name:         name,
+		location:     location,
+		entries:      []types.Entry{},
+		cursor:       0,
+		selected:     map[string]struct{}{},
+		fs:           fs,
+		transferFs:   fs,
+		topItemIndex: 0,
+		itemsInVew:   flDefaultItems,
+		width:        flDefaultWidth,
+		columns:      DefaultColumns,
+	}
+	if err := flm.Refresh(); err != nil {
+		return FileListModel{}, err
 	}
+	return flm, nil
 }
 
-func (f FileListModelBuilder) WithTransferFn(fn transferFn) FileListModelBuilder {
-	f.transferFn = fn
-	return f
+// SetSortOrder sorts the entries, cursor stays on the same entry
+func (m *FileListModel) SetSortOrder(order pkg.SortOrder) {
+	m.order = order
+	pkg.SortEntries(m.all, order)
+	m.applyFilter()
 }
 
-func (f FileListModelBuilder) WithDeleteFn(fn deleteFn) FileListModelBuilder {
-	f.deleteFn = fn
-	return f
+// SetFilter shows only entries matching the filter, cursor stays on the same entry when it matches,
+// selection of hidden entries is kept
+func (m *FileListModel) SetFilter(filter pkg.Filter) {
+	m.filter = filter
+	m.searching = false
+	m.applyFilter()
 }
 
-func (f FileListModelBuilder) Build() (FileListModel, error) {
-	return InitFileListModel(f.name, f.location, f.listFn, f.transferFn, f.deleteFn)
+func (m FileListModel) Filter() pkg.Filter {
+	return m.filter
 }
 
-type FileListModel struct {
-	name         string
-	location     string
-	entries      []types.Entry
-	cursor       int
-	selected     map[int]string
-	listFn       listFn
-	topItemIndex int
-	itemsInVew   int
-	transferFn   transferFn
-	deleteFn     deleteFn
+func (m *FileListModel) applyFilter() {
+	var cursorName string
+	if len(m.entries) > 0 {
+		cursorName = m.entries[m.cursor].Name
+	}
+	m.entries = make([]types.Entry, 0, len(m.all))
+	m.cursor = 0
+	for _, entry := range m.all {
+		if !m.searching && !m.filter.Match(entry.Name) {
+			continue
+		}
+		if entry.Name == cursorName {
+			m.cursor = len(m.entries)
+		}
+		m.entries = append(m.entries, entry)
+	}
+	m.SetSize(m.width, m.itemsInVew+flChromeHeight)
 }
 
-type listFn func(location string) ([]types.Entry, error)
-
-type transferFn func(string, []types.Entry, string) error
+// NextMatch moves cursor to the next entry matching the filter, from the last one to the first.
+// Entries hidden by the filter are shown again, so the matches are seen among them.
+func (m *FileListModel) NextMatch() {
+	m.moveToMatch(1)
+}
 
-type deleteFn func(location string, entries []types.Entry) error
+// PreviousMatch moves cursor to the previous entry matching the filter, from the first one to the last
+func (m *FileListModel) PreviousMatch() {
+	m.moveToMatch(-1)
+}
 
-var ErrNotSet = errors.New("function not set")
+func (m *FileListModel) moveToMatch(step int) {
+	if !m.filter.Active() {
+		return
+	}
+	if !m.searching {
+		m.searching = true
+		m.applyFilter()
+	}
+	count := len(m.entries)
+	for i := 1; i <= count; i++ {
+		index := ((m.cursor+step*i)%count + count) % count
+		if m.filter.Match(m.entries[index].Name) {
+			m.moveTo(index)
+			return
+		}
+	}
+}
 
-func InitFileListModel(name, location string, listFn listFn, transferFn transferFn, deleteFn deleteFn) (FileListModel, error) {
-	flm := FileListModel{
-		name:         name,
-		location:     location,
-		entries:      []types.Entry{},
-		cursor:       0,
-		selected:     map[int]string{},
-		listFn:       listFn,
-		topItemIndex: 0,
-		itemsInVew:   10,
-		transferFn:   transferFn,
-		deleteFn:     deleteFn,
+func (m *FileListModel) moveTo(index int) {
+	m.cursor = index
+	if m.cursor < m.topItemIndex {
+		m.topItemIndex = m.cursor
 	}
-	if err := flm.Refresh(); err != nil {
-		return FileListModel{}, err
+	if m.cursor > m.topItemIndex+m.itemsInVew-1 {
+		m.topItemIndex = m.cursor - m.itemsInVew + 1
 	}
-	return flm, nil
+}
+
+func (m FileListModel) SortOrder() pkg.SortOrder {
+	return m.order
+}
+
+// IsLocal reports whether the list browses the local disk
+func (m FileListModel) IsLocal() bool {
+	_, ok := m.fs.(pkg.LocalFS)
+	return ok
+}
+
+// SetColumns sets metadata shown next to names
+func (m *FileListModel) SetColumns(columns []Column) {
+	m.columns = columns
+}
+
+// SetSize fits the list into width and height of terminal cells
+func (m *FileListModel) SetSize(width, height int) {
+	m.width = pkg.Max(width, flChromeWidth+flSizeWidth+8)
+	m.itemsInVew = pkg.Max(height-flChromeHeight, 1)
+	// keep the cursor visible
+	if m.cursor > m.topItemIndex+m.itemsInVew-1 {
+		m.topItemIndex = m.cursor - m.itemsInVew + 1
+	}
+	m.topItemIndex = pkg.Max(0, pkg.Min(m.topItemIndex, m.cursor, len(m.entries)-m.itemsInVew))
 }
 
 func (m *FileListModel) Up() {
+	m.endRange()
+	m.up()
+}
+
+func (m *FileListModel) up() {
 	if m.cursor <= 0 {
 		return
 	}
@@ -92,6 +192,11 @@ func (m *FileListModel) Up() {
 }
 
 func (m *FileListModel) Down() {
+	m.endRange()
+	m.down()
+}
+
+func (m *FileListModel) down() {
 	if m.cursor >= len(m.entries)-1 {
 		return
 	}
@@ -102,14 +207,103 @@ func (m *FileListModel) Down() {
 }
 
 func (m *FileListModel) ToggleSelection() {
+	m.endRange()
 	if len(m.entries) == 0 {
 		return
 	}
-	if _, exists := m.selected[m.cursor]; exists {
-		delete(m.selected, m.cursor)
+	name := m.entries[m.cursor].Name
+	if _, exists := m.selected[name]; exists {
+		delete(m.selected, name)
 	} else {
-		m.selected[m.cursor] = m.entries[m.cursor].Name
+		m.selected[name] = struct{}{}
+	}
+}
+
+// SelectAll selects shown entries, the ones hidden by filter are left as they are
+func (m *FileListModel) SelectAll() {
+	m.endRange()
+	for _, entry := range m.entries {
+		m.selected[entry.Name] = struct{}{}
+	}
+}
+
+// InvertSelection toggles selection of shown entries
+func (m *FileListModel) InvertSelection() {
+	m.endRange()
+	for _, entry := range m.entries {
+		if _, exists := m.selected[entry.Name]; exists {
+			delete(m.selected, entry.Name)
+		} else {
+			m.selected[entry.Name] = struct{}{}
+		}
+	}
+}
+
+// SelectMatching selects or deselects shown entries whose name matches, returns number of them
+func (m *FileListModel) SelectMatching(match func(name string) bool, selected bool) int {
+	m.endRange()
+	count := 0
+	for _, entry := range m.entries {
+		if !match(entry.Name) {
+			continue
+		}
+		count++
+		if selected {
+			m.selected[entry.Name] = struct{}{}
+		} else {
+			delete(m.selected, entry.Name)
+		}
 	}
+	return count
+}
+
+// SelectUp moves the cursor up and selects the range from the entry it started on, moving back shrinks the range
+func (m *FileListModel) SelectUp() {
+	m.selectRange(m.up)
+}
+
+// SelectDown moves the cursor down and selects the range from the entry it started on, moving back shrinks the range
+func (m *FileListModel) SelectDown() {
+	m.selectRange(m.down)
+}
+
+// selectRange selects entries between the anchor and the cursor moved by move, selection outside the range is kept
+func (m *FileListModel) selectRange(move func()) {
+	if len(m.entries) == 0 {
+		return
+	}
+	anchor := m.indexOf(m.rangeAnchor)
+	if anchor < 0 || m.entries[m.cursor].Name != m.rangeEnd {
+		anchor = m.cursor
+		m.rangeAnchor = m.entries[anchor].Name
+		m.rangeBase = make(map[string]struct{}, len(m.selected))
+		for name := range m.selected {
+			m.rangeBase[name] = struct{}{}
+		}
+	}
+	move()
+	for name := range m.selected {
+		if _, base := m.rangeBase[name]; !base {
+			delete(m.selected, name)
+		}
+	}
+	for _, entry := range m.entries[pkg.Min(anchor, m.cursor) : pkg.Max(anchor, m.cursor)+1] {
+		m.selected[entry.Name] = struct{}{}
+	}
+	m.rangeEnd = m.entries[m.cursor].Name
+}
+
+func (m *FileListModel) endRange() {
+	m.rangeAnchor, m.rangeEnd, m.rangeBase = "", "", nil
+}
+
+func (m FileListModel) indexOf(name string) int {
+	for i, entry := range m.entries {
+		if entry.Name == name {
+			return i
+		}
+	}
+	return -1
 }
 
 func (m *FileListModel) Enter() error {
@@ -131,75 +325,245 @@ func (m *FileListModel) Refresh() error {
 }
 
 func (m *FileListModel) move(newLocation string) error {
-	if m.listFn == nil {
-		return nil
-	}
-	newEntries, err := m.listFn(newLocation)
+	newEntries, err := m.fs.List(newLocation)
 	if err != nil {
 		return err
 	}
-	sort.Slice(newEntries, func(i, j int) bool {
-		ei := newEntries[i]
-		ej := newEntries[j]
-		if ei.Type != ej.Type {
-			return ei.Type < ej.Type
-		}
-		return ei.Name < ej.Name
-	})
+	pkg.SortEntries(newEntries, m.order)
+	if newLocation == m.location {
+		// refresh keeps the cursor and filter in place
+		m.DeselectAll()
+		cursor := m.cursor
+		m.all = newEntries
+		m.applyFilter()
+		m.cursor = pkg.Min(cursor, pkg.Max(len(m.entries)-1, 0))
+		m.topItemIndex = pkg.Min(m.topItemIndex, m.cursor)
+		return nil
+	}
 	m.reset()
-	m.entries = newEntries
+	m.all = newEntries
 	m.location = newLocation
+	m.applyFilter()
 	return nil
 }
 
 func (m *FileListModel) reset() {
 	m.cursor = 0
 	m.topItemIndex = 0
+	m.filter = pkg.Filter{}
+	m.searching = false
+	m.entries = nil
 	m.DeselectAll()
 }
 
 func (m *FileListModel) DeselectAll() {
-	for i := range m.selected {
-		delete(m.selected, i)
+	m.endRange()
+	for name := range m.selected {
+		delete(m.selected, name)
 	}
 }
 
 func (m FileListModel) GetAllSelectedAbsolute() []string {
-	result := make([]string, 0, len(m.selected))
-	for i := range m.selected {
-		result = append(result, path.Join(m.location, m.entries[i].Name))
-	}
-	return result
+	return pkg.MapSlice(m.getAllSelected(), func(entry types.Entry) string {
+		return path.Join(m.location, entry.Name)
+	})
+}
+
+// SetTransferFS makes transfers from and to this list go through fsys
+func (m *FileListModel) SetTransferFS(fsys pkg.RemoteFS) {
+	m.transferFs = fsys
+}
+
+// EnqueueTransfer adds job copying each selected entry into current location of the destination
+func (m FileListModel) EnqueueTransfer(queue *pkg.TransferQueue, destination FileListModel, opts pkg.TransferOptions) {
+	queue.Add(m.transferFs, m.location, m.getAllSelected(), destination.transferFs, destination.location, opts)
 }
 
-func (m FileListModel) Transfer(destination string) error {
-	if m.transferFn == nil {
-		return fmt.Errorf("transfer %w", ErrNotSet)
+// PlanMirror compares current location with the one of destination, the plan is executed with the transfer filesystems
+func (m FileListModel) PlanMirror(destination FileListModel, opts pkg.MirrorOptions) (pkg.MirrorPlan, error) {
+	plan, err := pkg.PlanMirror(m.fs, m.location, destination.fs, destination.location, opts)
+	if err != nil {
+		return pkg.MirrorPlan{}, err
 	}
-	selected := m.getAllSelected()
-	if len(selected) == 0 {
+	plan.Source, plan.Destination = m.transferFs, destination.transferFs
+	return plan, nil
+}
+
+func (m *FileListModel) Delete() error {
+	if len(m.entries) == 0 {
 		return nil
 	}
-	return m.transferFn(m.location, selected, destination)
+	if err := pkg.RemoveAll(m.fs, m.location, m.getAllSelected()); err != nil {
+		return err
+	}
+	return m.move(m.location)
 }
 
-func (m *FileListModel) Delete() error {
-	if m.deleteFn == nil {
-		return fmt.Errorf("delete %w", ErrNotSet)
+// Current returns entry under cursor, false when the list is empty
+func (m FileListModel) Current() (types.Entry, bool) {
+	if len(m.entries) == 0 {
+		return types.Entry{}, false
 	}
+	return m.entries[m.cursor], true
+}
+
+// Rename renames entry under cursor, new name may be a path relative to the location to move the entry
+func (m *FileListModel) Rename(to string) error {
 	if len(m.entries) == 0 {
 		return nil
 	}
-	if err := m.deleteFn(m.location, m.getAllSelected()); err != nil {
+	from := m.entries[m.cursor].Name
+	if to == from {
+		return nil
+	}
+	if !strings.Contains(to, "/") {
+		if err := pkg.ValidateName(to); err != nil {
+			return err
+		}
+	}
+	if err := pkg.Move(m.fs, path.Join(m.location, from), m.absolute(to)); err != nil {
 		return err
 	}
-	return m.move(m.location)
+	return m.refreshTo(to)
+}
+
+// MakeDir creates directory and moves cursor to it, name may be a relative path creating missing parents
+func (m *FileListModel) MakeDir(name string) error {
+	if err := m.validateNewName(name); err != nil {
+		return err
+	}
+	location := m.absolute(name)
+	if err := pkg.MkdirAll(m.fs, path.Dir(location)); err != nil {
+		return err
+	}
+	if err := m.fs.Mkdir(location); err != nil {
+		return err
+	}
+	return m.refreshTo(name)
 }
 
+// CreateFile creates empty file and moves cursor to it, name may be a relative path to existing directory
+func (m *FileListModel) CreateFile(name string) error {
+	if err := m.validateNewName(name); err != nil {
+		return err
+	}
+	if err := pkg.CreateEmpty(m.fs, m.absolute(name)); err != nil {
+		return err
+	}
+	return m.refreshTo(name)
+}
+
+func (m FileListModel) validateNewName(name string) error {
+	if path.IsAbs(name) {
+		return fmt.Errorf("%q is not relative to the directory", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if err := pkg.ValidateName(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshTo refreshes entries and moves cursor to the first part of the relative location
+func (m *FileListModel) refreshTo(location string) error {
+	if err := m.Refresh(); err != nil {
+		return err
+	}
+	m.moveToName(strings.SplitN(location, "/", 2)[0])
+	return nil
+}
+
+// MoveSelected moves selected entries, or the one under cursor when none is selected, into directory destination
+func (m *FileListModel) MoveSelected(destination string) error {
+	destination = m.absolute(destination)
+	for _, entry := range m.SelectedOrCurrent() {
+		if err := pkg.Move(m.fs, path.Join(m.location, entry.Name), path.Join(destination, entry.Name)); err != nil {
+			return err
+		}
+	}
+	return m.Refresh()
+}
+
+// RenameSelected renames selected entries, or the one under cursor when none is selected,
+// see pkg.PlanRenames for the expression
+func (m *FileListModel) RenameSelected(expression string) error {
+	names := pkg.MapSlice(m.SelectedOrCurrent(), func(entry types.Entry) string {
+		return entry.Name
+	})
+	renames, err := pkg.PlanRenames(names, expression)
+	if err != nil {
+		return err
+	}
+	if err := pkg.RenameAll(m.fs, m.location, renames); err != nil {
+		return err
+	}
+	return m.Refresh()
+}
+
+// OpenFrom opens entry in the location for reading through the transfer filesystem, so browsing is not blocked
+func (m FileListModel) OpenFrom(entry types.Entry, offset uint64) (io.ReadCloser, error) {
+	return m.transferFs.OpenFrom(path.Join(m.location, entry.Name), offset)
+}
+
+// Stat reads current state of entry named name in the location
+func (m FileListModel) Stat(name string) (types.Entry, error) {
+	return m.fs.Stat(path.Join(m.location, name))
+}
+
+// Create creates or truncates file named name in the location through the transfer filesystem
+func (m FileListModel) Create(name string) (io.WriteCloser, error) {
+	return m.transferFs.Create(path.Join(m.location, name))
+}
+
+// EntryMode returns permissions of entry in the location
+func (m FileListModel) EntryMode(entry types.Entry) (os.FileMode, error) {
+	return pkg.EntryMode(m.fs, m.location, entry)
+}
+
+// Chmod changes permissions of selected entries, or the one under cursor when none is selected
+func (m *FileListModel) Chmod(change pkg.ModeChange, recursive bool) error {
+	if err := pkg.ChmodAll(m.fs, m.location, m.SelectedOrCurrent(), change, recursive); err != nil {
+		return err
+	}
+	return m.Refresh()
+}
+
+// absolute resolves location relative to the current one
+func (m FileListModel) absolute(location string) string {
+	if path.IsAbs(location) {
+		return path.Clean(location)
+	}
+	return path.Join(m.location, location)
+}
+
+func (m *FileListModel) moveToName(name string) {
+	for i, entry := range m.entries {
+		if entry.Name == name {
+			m.moveTo(i)
+			return
+		}
+	}
+}
+
+// SelectedOrCurrent returns selected entries, or the one under cursor when none is selected
+func (m FileListModel) SelectedOrCurrent() []types.Entry {
+	if len(m.selected) > 0 {
+		return m.getAllSelected()
+	}
+	if len(m.entries) == 0 {
+		return nil
+	}
+	return []types.Entry{m.entries[m.cursor]}
+}
+
+// getAllSelected returns selected entries including the ones hidden by filter
 func (m FileListModel) getAllSelected() []types.Entry {
 	selected := make([]types.Entry, 0, len(m.selected))
-	for i := range m.selected {
-		selected = append(selected, m.entries[i])
+	for _, entry := range m.all {
+		if _, ok := m.selected[entry.Name]; ok {
+			selected = append(selected, entry)
+		}
 	}
 	return selected
 }
@@ -208,11 +572,28 @@ func (m FileListModel) GetSelectedCount() int {
 	return len(m.selected)
 }
 
+// GetSelectedSize sums sizes of selected files, directories are not walked
+func (m FileListModel) GetSelectedSize() uint64 {
+	var size uint64
+	for _, entry := range m.getAllSelected() {
+		if entry.Type != types.TypeDirectory {
+			size += entry.Size
+		}
+	}
+	return size
+}
+
 func (m FileListModel) GetLocation() string {
 	return m.location
 }
 
 func (m FileListModel) View(showCursorSelected bool) string {
+	contentWidth := m.width - flChromeWidth
+	prefixWidth := 1
+	if showCursorSelected {
+		prefixWidth = lipgloss.Width(" [ ]  d")
+	}
+	columns := m.visibleColumns(contentWidth - prefixWidth)
 	lines := make([]string, 0, m.itemsInVew)
 	for i := m.topItemIndex; i < pkg.Min(m.topItemIndex+m.itemsInVew, len(m.entries)); i++ {
 		entry := m.entries[i]
@@ -222,40 +603,91 @@ func (m FileListModel) View(showCursorSelected bool) string {
 		}
 
 		selected := "[ ]"
-		if _, ok := m.selected[i]; ok {
+		if _, ok := m.selected[entry.Name]; ok {
 			selected = "[x]"
 		}
 
-		sizeView := lipgloss.
-			NewStyle().
-			Align(lipgloss.Right).
-			Width(12).
-			Render(pkg.PrettyPrintSize(entry.Size))
-
-		nameView := lipgloss.NewStyle().
-			Padding(0, 0, 0, 2).
-			Render(entry.Name)
-
-		item := lipgloss.JoinHorizontal(lipgloss.Bottom, entry.TypeString(), sizeView, nameView)
+		prefix := entry.TypeString()
 		if showCursorSelected {
 			typeView := lipgloss.NewStyle().
 				Padding(0, 0, 0, 2).
 				Render(entry.TypeString())
 
-			item = lipgloss.JoinHorizontal(lipgloss.Bottom, cursor, selected, typeView, sizeView, nameView)
+			prefix = lipgloss.JoinHorizontal(lipgloss.Bottom, cursor, selected, typeView)
 		}
+		views := []string{prefix}
+		nameWidth := contentWidth - prefixWidth - flColumnGap
+		for _, column := range columns {
+			views = append(views, lipgloss.NewStyle().
+				Padding(0, 0, 0, flColumnGap).
+				Render(column.render(entry)))
+			nameWidth -= column.width() + flColumnGap
+		}
+		name := entry.Name
+		if entry.LinkTarget != "" {
+			name = fmt.Sprintf("%s -> %s", name, entry.LinkTarget)
+		}
+		views = append(views, lipgloss.NewStyle().
+			Padding(0, 0, 0, flColumnGap).
+			Render(pkg.Ellipsize(name, nameWidth)))
+
+		item := lipgloss.JoinHorizontal(lipgloss.Bottom, views...)
 
 		lines = append(lines, item)
 	}
 
 	return lipgloss.JoinVertical(
 		lipgloss.Center,
-		fmt.Sprintf("%s:%s", m.name, m.location),
+		m.header(),
 		lipgloss.NewStyle().
 			Height(m.itemsInVew).
+			Width(m.width-2).
 			Border(lipgloss.NormalBorder(), true).
 			Padding(0, 1).
 			Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
-		fmt.Sprintf("[%d-%d]/%d", m.topItemIndex+1, m.topItemIndex+m.itemsInVew, len(m.entries)),
+		m.footer(),
 	)
 }
+
+// visibleColumns drops columns from the end until name fits into width
+func (m FileListModel) visibleColumns(width int) []Column {
+	columns := m.columns
+	for len(columns) > 0 {
+		used := 0
+		for _, column := range columns {
+			used += column.width() + flColumnGap
+		}
+		if width-used-flColumnGap >= flMinNameWidth {
+			break
+		}
+		columns = columns[:len(columns)-1]
+	}
+	return columns
+}
+
+// header shows the location and sort order, the location is shortened when it does not fit
+func (m FileListModel) header() string {
+	suffix := fmt.Sprintf(" [%s]", m.order)
+	switch {
+	case m.filter.Active() && m.searching:
+		suffix = fmt.Sprintf("%s search %s /%s", suffix, m.filter.Mode, m.filter.Pattern)
+	case m.filter.Active():
+		suffix = fmt.Sprintf("%s %s /%s", suffix, m.filter.Mode, m.filter.Pattern)
+	}
+	location := pkg.EllipsizeLeft(fmt.Sprintf("%s:%s", m.name, m.location), m.width-lipgloss.Width(suffix))
+	return pkg.Ellipsize(location+suffix, m.width)
+}
+
+// footer shows the range of visible entries, with entries hidden by filter also number of all of them
+func (m FileListModel) footer() string {
+	footer := fmt.Sprintf(
+		"[%d-%d]/%d",
+		pkg.Min(m.topItemIndex+1, len(m.entries)),
+		pkg.Min(m.topItemIndex+m.itemsInVew, len(m.entries)),
+		len(m.entries),
+	)
+	if len(m.entries) != len(m.all) {
+		footer = fmt.Sprintf("%s of %d", footer, len(m.all))
+	}
+	return footer
+}
@@ -0,0 +1,118 @@
+package components
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/prathoss/goftp/pkg"
+)
+
+// TransferQueueMsg is sent when jobs in the queue changed
+type TransferQueueMsg struct {
+	// Finished is set when some job finished since the previous message
+	Finished bool
+}
+
+// TransferModel renders progress of the transfer queue
+type TransferModel struct {
+	queue    *pkg.TransferQueue
+	bar      progress.Model
+	finished map[int]struct{}
+	width    int
+}
+
+func InitTransferModel(queue *pkg.TransferQueue) TransferModel {
+	return TransferModel{
+		queue:    queue,
+		bar:      progress.New(progress.WithDefaultGradient()),
+		finished: map[int]struct{}{},
+		width:    progress.New().Width,
+	}
+}
+
+// Listen returns command waiting for the next change of the queue,
+// it must be issued again after each TransferQueueMsg
+func (m TransferModel) Listen() tea.Cmd {
+	return func() tea.Msg {
+		<-m.queue.Updates()
+		finished := false
+		for _, job := range m.queue.Jobs() {
+			if _, seen := m.finished[job.ID]; job.State.Finished() && !seen {
+				finished = true
+			}
+		}
+		return TransferQueueMsg{Finished: finished}
+	}
+}
+
+func (m TransferModel) Update(msg tea.Msg) (TransferModel, tea.Cmd) {
+	switch msg.(type) {
+	case TransferQueueMsg:
+		finished := make(map[int]struct{}, len(m.finished))
+		for _, job := range m.queue.Jobs() {
+			if job.State.Finished() {
+				finished[job.ID] = struct{}{}
+			}
+		}
+		m.finished = finished
+		return m, m.Listen()
+	}
+	return m, nil
+}
+
+// TransferViewHeight is the most lines View takes
+const TransferViewHeight = 4
+
+// SetWidth fits the view into width of terminal cells
+func (m *TransferModel) SetWidth(width int) {
+	m.width = width
+	m.bar.Width = width
+}
+
+func (m TransferModel) Queue() *pkg.TransferQueue {
+	return m.queue
+}
+
+func (m TransferModel) View() string {
+	var active *pkg.TransferJob
+	pending, failed := 0, 0
+	jobs := m.queue.Jobs()
+	for i, job := range jobs {
+		switch job.State {
+		case pkg.JobActive:
+			active = &jobs[i]
+		case pkg.JobPending:
+			pending++
+		case pkg.JobFailed:
+			failed++
+		}
+	}
+	if active == nil && pending == 0 && failed == 0 {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("Queued: %d  Failed: %d", pending, failed)}
+	if active != nil && active.Remove {
+		lines = append(lines, pkg.Ellipsize(fmt.Sprintf("Deleting %s", path.Base(active.DestinationPath())), m.width))
+	} else if active != nil {
+		p := active.Progress
+		file := active.SourcePath()
+		if p.File != "" {
+			file = p.File
+		}
+		lines = append(lines,
+			pkg.Ellipsize(fmt.Sprintf("Transferring %s", path.Base(file)), m.width),
+			m.bar.ViewAs(p.Percent()),
+			fmt.Sprintf(
+				"%s / %s  %s/s  ETA %s",
+				pkg.PrettyPrintSize(p.Done),
+				pkg.PrettyPrintSize(p.Total),
+				pkg.PrettyPrintSize(uint64(p.Rate())),
+				p.ETA(),
+			),
+		)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,189 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/prathoss/goftp/types"
+)
+
+// ModeReader reads permissions on filesystems whose listing does not contain them
+type ModeReader interface {
+	Mode(location string) (os.FileMode, error)
+}
+
+var ErrModeUnknown = errors.New("current permissions are unknown, use octal mode")
+
+// ModeChange changes permission bits, it is either absolute octal mode like 755
+// or symbolic clauses like u+x,go-w,a=rX relative to the current mode
+type ModeChange struct {
+	octal   os.FileMode
+	clauses []modeClause
+}
+
+type modeClause struct {
+	// who is mask of affected permission bits
+	who os.FileMode
+	op  byte
+	// perm has bits for all classes, X is kept separately as it depends on the entry
+	perm        os.FileMode
+	conditional bool
+}
+
+func ParseModeChange(s string) (ModeChange, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ModeChange{}, errors.New("empty mode")
+	}
+	if octal, err := strconv.ParseUint(s, 8, 32); err == nil {
+		if octal > 0777 {
+			return ModeChange{}, fmt.Errorf("mode %q is out of range 000-777", s)
+		}
+		return ModeChange{octal: os.FileMode(octal)}, nil
+	}
+	var change ModeChange
+	for _, part := range strings.Split(s, ",") {
+		clause, err := parseModeClause(part)
+		if err != nil {
+			return ModeChange{}, err
+		}
+		change.clauses = append(change.clauses, clause)
+	}
+	return change, nil
+}
+
+func parseModeClause(s string) (modeClause, error) {
+	var clause modeClause
+	i := 0
+	for ; i < len(s) && strings.IndexByte("ugoa", s[i]) >= 0; i++ {
+		switch s[i] {
+		case 'u':
+			clause.who |= 0700
+		case 'g':
+			clause.who |= 0070
+		case 'o':
+			clause.who |= 0007
+		case 'a':
+			clause.who |= 0777
+		}
+	}
+	if clause.who == 0 {
+		clause.who = 0777
+	}
+	if i >= len(s) || strings.IndexByte("+-=", s[i]) < 0 {
+		return modeClause{}, fmt.Errorf("invalid mode %q, expected octal like 755 or symbolic like u+x,go-w", s)
+	}
+	clause.op = s[i]
+	for _, r := range s[i+1:] {
+		switch r {
+		case 'r':
+			clause.perm |= 0444
+		case 'w':
+			clause.perm |= 0222
+		case 'x':
+			clause.perm |= 0111
+		case 'X':
+			clause.conditional = true
+		default:
+			return modeClause{}, fmt.Errorf("invalid permission %q in mode %q", r, s)
+		}
+	}
+	return clause, nil
+}
+
+// Relative reports whether the result depends on the current mode
+func (c ModeChange) Relative() bool {
+	for _, clause := range c.clauses {
+		// = sets all bits of the class, but other classes are kept
+		if clause.op != '=' || clause.who != 0777 {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the changed mode, X sets execute for directories and files executable by anyone
+func (c ModeChange) Apply(mode os.FileMode, isDir bool) os.FileMode {
+	if c.clauses == nil {
+		return c.octal
+	}
+	mode = mode.Perm()
+	for _, clause := range c.clauses {
+		perm := clause.perm
+		if clause.conditional && (isDir || mode&0111 != 0) {
+			perm |= 0111
+		}
+		perm &= clause.who
+		switch clause.op {
+		case '+':
+			mode |= perm
+		case '-':
+			mode &^= perm
+		case '=':
+			mode = mode&^clause.who | perm
+		}
+	}
+	return mode
+}
+
+// EntryMode returns permissions of entry in location, they are read by ModeReader when the listing has none
+func EntryMode(fsys RemoteFS, location string, entry types.Entry) (os.FileMode, error) {
+	if entry.Mode != 0 {
+		return entry.Mode.Perm(), nil
+	}
+	if reader, ok := fsys.(ModeReader); ok {
+		return reader.Mode(path.Join(location, entry.Name))
+	}
+	return 0, ErrModeUnknown
+}
+
+// ChmodAll changes permissions of entries in location, content of directories is changed when recursive.
+// Links are skipped, changing them would change their targets.
+func ChmodAll(fsys RemoteFS, location string, entries []types.Entry, change ModeChange, recursive bool) error {
+	for _, entry := range entries {
+		if entry.Type == types.TypeLink {
+			continue
+		}
+		absolutePath := path.Join(location, entry.Name)
+		var mode os.FileMode
+		if change.Relative() {
+			var err error
+			if mode, err = EntryMode(fsys, location, entry); err != nil {
+				return fmt.Errorf("%s: %w", absolutePath, err)
+			}
+		}
+		isDir := entry.Type == types.TypeDirectory
+		newMode := change.Apply(mode, isDir)
+		if !recursive || !isDir {
+			if err := fsys.Chmod(absolutePath, newMode); err != nil {
+				return err
+			}
+			continue
+		}
+		// directory has to stay listable while its content is changed,
+		// so it is changed last when it loses read or execute permission for the owner
+		last := newMode&0500 != 0500
+		if !last {
+			if err := fsys.Chmod(absolutePath, newMode); err != nil {
+				return err
+			}
+		}
+		children, err := fsys.List(absolutePath)
+		if err != nil {
+			return err
+		}
+		if err := ChmodAll(fsys, absolutePath, children, change, recursive); err != nil {
+			return err
+		}
+		if last {
+			if err := fsys.Chmod(absolutePath, newMode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
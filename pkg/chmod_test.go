@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseModeChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   string
+		mode     os.FileMode
+		isDir    bool
+		want     os.FileMode
+		relative bool
+		wantErr  bool
+	}{
+		{name: "octal", change: "755", mode: 0600, want: 0755},
+		{name: "octal with leading zero", change: "0640", mode: 0777, want: 0640},
+		{name: "octal is trimmed", change: " 700 ", mode: 0, want: 0700},
+		{name: "add for owner", change: "u+x", mode: 0644, want: 0744, relative: true},
+		{name: "remove for group and others", change: "go-w", mode: 0666, want: 0644, relative: true},
+		{name: "no class means all", change: "+x", mode: 0644, want: 0755, relative: true},
+		{name: "all", change: "a-r", mode: 0644, want: 0200, relative: true},
+		{name: "set keeps other classes", change: "o=r", mode: 0777, want: 0774, relative: true},
+		{name: "set without permissions clears", change: "go=", mode: 0755, want: 0700, relative: true},
+		{name: "set all is absolute", change: "a=rw", mode: 0111, want: 0666},
+		{name: "set all without class is absolute", change: "=r", mode: 0777, want: 0444},
+		{name: "comma list applies in order", change: "a=r,u+w", mode: 0777, want: 0644, relative: true},
+		{name: "several classes and permissions", change: "ug+rwx,o-rwx", mode: 0004, want: 0770, relative: true},
+		{name: "X on directory", change: "a+X", mode: 0644, isDir: true, want: 0755, relative: true},
+		{name: "X on file executable by someone", change: "go+X", mode: 0744, want: 0755, relative: true},
+		{name: "X on file not executable", change: "a+X", mode: 0644, want: 0644, relative: true},
+		{name: "X removed from directory", change: "o-X", mode: 0755, isDir: true, want: 0754, relative: true},
+		{name: "set rX", change: "a=rX", mode: 0700, isDir: true, want: 0555},
+		{name: "type bits are dropped", change: "u+w", mode: os.ModeDir | 0555, isDir: true, want: 0755, relative: true},
+		{name: "empty", change: "", wantErr: true},
+		{name: "octal out of range", change: "1777", wantErr: true},
+		{name: "missing operator", change: "u", wantErr: true},
+		{name: "unknown class", change: "z+x", wantErr: true},
+		{name: "unknown permission", change: "u+s", wantErr: true},
+		{name: "empty clause", change: "u+x,", wantErr: true},
+		{name: "not octal digits", change: "789", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, err := ParseModeChange(tt.change)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseModeChange(%q) error = %v, wantErr %v", tt.change, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := change.Apply(tt.mode, tt.isDir); got != tt.want {
+				t.Errorf("Apply(%o) = %o, want %o", tt.mode, got, tt.want)
+			}
+			if got := change.Relative(); got != tt.relative {
+				t.Errorf("Relative() = %v, want %v", got, tt.relative)
+			}
+		})
+	}
+}
@@ -2,50 +2,294 @@ package pkg
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"net"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+type Protocol string
+
+const (
+	ProtocolFtp  Protocol = ""
+	ProtocolSftp Protocol = "sftp"
+)
+
+// Protocols lists protocols in the order they are offered on the login screen
+var Protocols = []Protocol{ProtocolFtp, ProtocolSftp}
+
+func (p Protocol) String() string {
+	if p == ProtocolFtp {
+		return "ftp"
+	}
+	return string(p)
+}
+
+func ParseProtocol(s string) (Protocol, error) {
+	for _, protocol := range Protocols {
+		if protocol.String() == s || string(protocol) == s {
+			return protocol, nil
+		}
+	}
+	return ProtocolFtp, fmt.Errorf("unknown protocol %q", s)
+}
+
 type ServerConf struct {
-	Server string
-	Port   int
-	User   string
+	Protocol Protocol `yaml:"protocol,omitempty"`
+	Server   string
+	Port     int
+	User     string
+	TLS      TLSConf `yaml:"tls,omitempty"`
+	// KeyFile is private key used for sftp authentication
+	KeyFile string `yaml:"keyFile,omitempty"`
+	// Connections is number of connections used for transfers, zero means DefaultConnections
+	Connections int `yaml:"connections,omitempty"`
+	// Conflict is default policy for files which already exist at the destination
+	Conflict ConflictPolicy `yaml:"conflict,omitempty"`
+	// PasswordStore is where the password is remembered, it is not remembered by default
+	PasswordStore PasswordStore `yaml:"passwordStore,omitempty"`
+	// Name is shown instead of the address
+	Name string `yaml:"name,omitempty"`
+	// Group puts the connection into a folder of saved connections
+	Group string `yaml:"group,omitempty"`
+	// RemotePath is absolute directory the remote panel starts in, root by default
+	RemotePath string `yaml:"remotePath,omitempty"`
+	// LocalPath is directory the local panel starts in, working directory by default
+	LocalPath string `yaml:"localPath,omitempty"`
+	// Timeout is in seconds, zero means DefaultTimeout
+	Timeout int `yaml:"timeout,omitempty"`
+	// DataMode and TransferType apply to ftp only
+	DataMode     DataMode     `yaml:"dataMode,omitempty"`
+	TransferType TransferType `yaml:"transferType,omitempty"`
+	// LocalSort and RemoteSort are remembered sort orders of the panels
+	LocalSort  SortOrder `yaml:"localSort,omitempty"`
+	RemoteSort SortOrder `yaml:"remoteSort,omitempty"`
+}
+
+// DefaultTimeout of connecting to the server in seconds
+const DefaultTimeout = 5
+
+// ID identifies the connection in password stores, ftp connections with different tls modes are different ones
+func (c ServerConf) ID() string {
+	id := fmt.Sprintf("%s://%s@%s:%d", c.Scheme(), c.User, c.Server, c.Port)
+	if c.Protocol == ProtocolFtp && c.TLS.Mode != TLSModeNone {
+		id += "?tls=" + string(c.TLS.Mode)
+	}
+	return id
+}
+
+// Address is url of the connection without path
+func (c ServerConf) Address() string {
+	return fmt.Sprintf("%s://%s@%s", c.Scheme(), c.User, c.Server)
+}
+
+// ParseServerURL reads connection from url in form scheme://[user[:password]@]server[:port][/path],
+// scheme is ftp, ftps (explicit tls) or sftp, ipv6 server is in brackets
+func ParseServerURL(raw string) (conf ServerConf, password string, location string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ServerConf{}, "", "", err
+	}
+	switch u.Scheme {
+	case "ftp":
+	case "ftps":
+		conf.TLS.Mode = TLSModeExplicit
+	case "sftp":
+		conf.Protocol = ProtocolSftp
+	default:
+		return ServerConf{}, "", "", fmt.Errorf("unsupported url scheme %q, use ftp, ftps or sftp", u.Scheme)
+	}
+	conf.Server = u.Hostname()
+	if conf.Server == "" {
+		return ServerConf{}, "", "", errors.New("url has no server")
+	}
+	// otherwise the last part of the address would be taken as port
+	if strings.Contains(conf.Server, ":") && !strings.HasPrefix(u.Host, "[") {
+		return ServerConf{}, "", "", fmt.Errorf("ipv6 address %q has to be enclosed in brackets", u.Host)
+	}
+	conf.Port = conf.DefaultPort()
+	if port := u.Port(); port != "" {
+		if conf.Port, err = strconv.Atoi(port); err != nil {
+			return ServerConf{}, "", "", fmt.Errorf("invalid port %q", port)
+		}
+	}
+	if u.User != nil {
+		conf.User = u.User.Username()
+		password, _ = u.User.Password()
+	}
+	if u.Path != "" && u.Path != "/" {
+		location = path.Clean(u.Path)
+	}
+	return conf, password, location, nil
+}
+
+// SameServer reports whether both connections log in as the same user to the same server
+func (c ServerConf) SameServer(other ServerConf) bool {
+	return c.Scheme() == other.Scheme() && c.Server == other.Server && c.Port == other.Port && c.User == other.User
+}
+
+// Title is the name or the address when there is no name
+func (c ServerConf) Title() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Address()
+}
+
+// Scheme is url scheme of the connection
+func (c ServerConf) Scheme() string {
+	if c.Protocol == ProtocolFtp && c.TLS.Mode != TLSModeNone {
+		return "ftps"
+	}
+	return c.Protocol.String()
+}
+
+func (c ServerConf) DefaultPort() int {
+	if c.Protocol == ProtocolSftp {
+		return 22
+	}
+	return c.TLS.Mode.DefaultPort()
+}
+
+// DialAddress is host and port to connect to, ipv6 address is enclosed in brackets
+func (c ServerConf) DialAddress() string {
+	return net.JoinHostPort(c.Server, strconv.Itoa(c.Port))
+}
+
+func (c ServerConf) DialTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout * time.Second
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// InitialRemotePath is the directory remote panel starts in
+func (c ServerConf) InitialRemotePath() string {
+	if c.RemotePath == "" {
+		return "/"
+	}
+	return c.RemotePath
+}
+
+// InitialLocalPath is the directory local panel starts in, leading ~ is the home directory
+func (c ServerConf) InitialLocalPath() (string, error) {
+	switch {
+	case c.LocalPath == "":
+		return os.Getwd()
+	case c.LocalPath == "~" || strings.HasPrefix(c.LocalPath, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, c.LocalPath[1:]), nil
+	default:
+		return filepath.Abs(c.LocalPath)
+	}
+}
+
+// TransferConnections is the size of transfer connection pool, the browsing connection is not counted
+func (c ServerConf) TransferConnections() int {
+	if c.Connections == 0 {
+		return DefaultConnections
+	}
+	return Max(MinConnections, Min(c.Connections, MaxConnections))
 }
 
 type Conf struct {
 	Servers []ServerConf
+	// Columns are shown next to file names, see components.Columns
+	Columns []string `yaml:"columns,omitempty"`
 }
 
 func (c Conf) ServerExists(newServer ServerConf) bool {
-	for _, server := range c.Servers {
-		if server == newServer {
-			return true
+	return c.find(newServer) >= 0
+}
+
+func (c Conf) find(conf ServerConf) int {
+	for i, server := range c.Servers {
+		if server == conf {
+			return i
 		}
 	}
-	return false
+	return -1
 }
 
-func getConfFilePath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+var ErrServerNotFound = errors.New("connection is not saved")
+
+// SaveServer replaces old connection with conf, when old is not saved conf is added unless it already exists
+func (c *Conf) SaveServer(old, conf ServerConf) {
+	if i := c.find(old); i >= 0 {
+		c.Servers[i] = conf
+		return
+	}
+	if !c.ServerExists(conf) {
+		c.Servers = append(c.Servers, conf)
 	}
-	return path.Join(home, ".goftp.yaml"), nil
 }
 
-func getConfFile() (*os.File, error) {
-	filePath, err := getConfFilePath()
-	if err != nil {
-		return nil, err
+// UpdateServer replaces saved connection old with conf
+func (c *Conf) UpdateServer(old, conf ServerConf) error {
+	i := c.find(old)
+	if i < 0 {
+		return ErrServerNotFound
+	}
+	c.Servers[i] = conf
+	return nil
+}
+
+func (c *Conf) RemoveServer(conf ServerConf) error {
+	i := c.find(conf)
+	if i < 0 {
+		return ErrServerNotFound
+	}
+	c.Servers = append(c.Servers[:i], c.Servers[i+1:]...)
+	return nil
+}
+
+// InsertServerAfter adds conf right after the existing connection
+func (c *Conf) InsertServerAfter(existing, conf ServerConf) error {
+	i := c.find(existing)
+	if i < 0 {
+		return ErrServerNotFound
+	}
+	c.Servers = append(c.Servers[:i+1], append([]ServerConf{conf}, c.Servers[i+1:]...)...)
+	return nil
+}
+
+// MoveServer swaps the connection with the previous or next one of the same group, depending on sign of offset
+func (c *Conf) MoveServer(conf ServerConf, offset int) error {
+	i := c.find(conf)
+	if i < 0 {
+		return ErrServerNotFound
+	}
+	step := 1
+	if offset < 0 {
+		step = -1
 	}
-	f, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, 0644)
+	for j := i + step; j >= 0 && j < len(c.Servers); j += step {
+		if c.Servers[j].Group == conf.Group {
+			c.Servers[i], c.Servers[j] = c.Servers[j], c.Servers[i]
+			return nil
+		}
+	}
+	return nil
+}
+
+func getConfFilePath() (string, error) {
+	home, err := os.UserHomeDir()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return f, nil
+	return path.Join(home, ".goftp.yaml"), nil
 }
 
 func readConfig(r io.Reader) (Conf, error) {
@@ -56,45 +300,54 @@ func readConfig(r io.Reader) (Conf, error) {
 	return cfg, nil
 }
 
-func writeConfig(cfg Conf, w io.WriteSeeker) error {
-	if _, err := w.Seek(0, 0); err != nil {
+// writeConfig writes into temporary file which replaces the config, so it is never left half written
+func writeConfig(cfg Conf, filePath string) error {
+	content, err := yaml.Marshal(cfg)
+	if err != nil {
 		return err
 	}
-	if err := yaml.NewEncoder(w).Encode(cfg); err != nil {
+	tmp := filePath + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filePath); err != nil {
+		_ = os.Remove(tmp)
 		return err
 	}
 	return nil
 }
 
 func GetConfig() (Conf, error) {
-	cf, err := getConfFile()
+	filePath, err := getConfFilePath()
 	if err != nil {
 		return Conf{}, err
 	}
-	defer cf.Close()
-	c, err := readConfig(cf)
+	f, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Conf{}, nil
+	}
 	if err != nil {
 		return Conf{}, err
 	}
-	return c, nil
+	defer f.Close()
+	return readConfig(f)
 }
 
-func AddToConfig(conf ServerConf) error {
-	f, err := getConfFile()
+// UpdateConfig reads the config, changes it by update and writes it back, nothing is written when update fails
+func UpdateConfig(update func(cfg *Conf) error) (Conf, error) {
+	filePath, err := getConfFilePath()
 	if err != nil {
-		return err
+		return Conf{}, err
 	}
-	defer f.Close()
-	c, err := readConfig(f)
+	cfg, err := GetConfig()
 	if err != nil {
-		return err
+		return Conf{}, err
 	}
-	if c.ServerExists(conf) {
-		return nil
+	if err := update(&cfg); err != nil {
+		return Conf{}, err
 	}
-	c.Servers = append(c.Servers, conf)
-	if err := writeConfig(c, f); err != nil {
-		return err
+	if err := writeConfig(cfg, filePath); err != nil {
+		return Conf{}, err
 	}
-	return nil
+	return cfg, nil
 }
@@ -0,0 +1,137 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/prathoss/goftp/types"
+)
+
+// ConflictPolicy decides what happens with a file which already exists at the destination,
+// existing directories are always merged
+type ConflictPolicy string
+
+const (
+	ConflictAsk         ConflictPolicy = ""
+	ConflictOverwrite   ConflictPolicy = "overwrite"
+	ConflictSkip        ConflictPolicy = "skip"
+	ConflictRename      ConflictPolicy = "rename"
+	ConflictNewer       ConflictPolicy = "newer"
+	ConflictSizeDiffers ConflictPolicy = "sizeDiffers"
+	// ConflictResume continues files shorter than the source from their size,
+	// files of the same size are skipped, larger ones are overwritten
+	ConflictResume ConflictPolicy = "resume"
+)
+
+// ConflictPolicies lists policies in the order they are offered to user
+var ConflictPolicies = []ConflictPolicy{
+	ConflictAsk,
+	ConflictOverwrite,
+	ConflictSkip,
+	ConflictRename,
+	ConflictNewer,
+	ConflictSizeDiffers,
+	ConflictResume,
+}
+
+func (p ConflictPolicy) String() string {
+	if p == ConflictAsk {
+		return "ask"
+	}
+	return string(p)
+}
+
+// Description is human readable form of the policy
+func (p ConflictPolicy) Description() string {
+	switch p {
+	case ConflictAsk:
+		return "Ask"
+	case ConflictOverwrite:
+		return "Overwrite"
+	case ConflictSkip:
+		return "Skip"
+	case ConflictRename:
+		return "Keep both, rename with suffix"
+	case ConflictNewer:
+		return "Overwrite if newer"
+	case ConflictSizeDiffers:
+		return "Overwrite if size differs"
+	default:
+		return "Resume"
+	}
+}
+
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	for _, policy := range ConflictPolicies {
+		if policy.String() == s || string(policy) == s {
+			return policy, nil
+		}
+	}
+	return ConflictAsk, fmt.Errorf("unknown conflict policy %q", s)
+}
+
+// Conflict is a file being transferred to location where a file already exists
+type Conflict struct {
+	SourcePath      string
+	Source          types.Entry
+	DestinationPath string
+	Destination     types.Entry
+}
+
+// ConflictResolver decides a conflict of ConflictAsk policy, it must not return ConflictAsk,
+// when applyToAll is set the policy is used for the rest of the transfer
+type ConflictResolver func(ctx context.Context, conflict Conflict) (policy ConflictPolicy, applyToAll bool, err error)
+
+var errConflictUnresolved = errors.New("destination exists and conflict policy is ask")
+
+// resolveConflict returns where and from which offset the file is written, skip is set when the file is not copied,
+// ConflictAsk policy must be resolved before
+func resolveConflict(fsys RemoteFS, policy ConflictPolicy, conflict Conflict) (destinationPath string, offset uint64, skip bool, err error) {
+	source, destination := conflict.Source, conflict.Destination
+	switch policy {
+	case ConflictOverwrite:
+		return conflict.DestinationPath, 0, false, nil
+	case ConflictSkip:
+		return "", 0, true, nil
+	case ConflictRename:
+		destinationPath, err := freeName(fsys, conflict.DestinationPath)
+		return destinationPath, 0, false, err
+	case ConflictNewer:
+		return conflict.DestinationPath, 0, !source.ModTime.After(destination.ModTime), nil
+	case ConflictSizeDiffers:
+		return conflict.DestinationPath, 0, source.Size == destination.Size, nil
+	case ConflictResume:
+		// larger destination is not a partial copy
+		if destination.Size > source.Size {
+			return conflict.DestinationPath, 0, false, nil
+		}
+		return conflict.DestinationPath, destination.Size, destination.Size == source.Size, nil
+	default:
+		return "", 0, false, errConflictUnresolved
+	}
+}
+
+// freeName finds name which does not exist by adding " (n)" suffix before the extension
+func freeName(fsys RemoteFS, location string) (string, error) {
+	dir, name := path.Split(location)
+	ext := path.Ext(name)
+	if ext == name {
+		// hidden files like .profile have no extension
+		ext = ""
+	}
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		candidate := path.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+		_, err := fsys.Stat(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
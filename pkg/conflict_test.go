@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prathoss/goftp/types"
+)
+
+func TestResolveConflict(t *testing.T) {
+	older := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	tests := []struct {
+		name        string
+		policy      ConflictPolicy
+		existing    []string
+		destination string
+		source      types.Entry
+		dest        types.Entry
+		wantPath    string
+		wantOffset  uint64
+		wantSkip    bool
+		wantErr     bool
+	}{
+		{
+			name:        "overwrite",
+			policy:      ConflictOverwrite,
+			destination: "a.txt",
+			source:      types.Entry{Size: 1},
+			dest:        types.Entry{Size: 10},
+			wantPath:    "a.txt",
+		},
+		{
+			name:        "skip",
+			policy:      ConflictSkip,
+			destination: "a.txt",
+			wantSkip:    true,
+		},
+		{
+			name:        "rename before extension",
+			policy:      ConflictRename,
+			existing:    []string{"a.txt"},
+			destination: "a.txt",
+			wantPath:    "a (1).txt",
+		},
+		{
+			name:        "rename skips taken suffixes",
+			policy:      ConflictRename,
+			existing:    []string{"a.tar.gz", "a.tar (1).gz", "a.tar (2).gz"},
+			destination: "a.tar.gz",
+			wantPath:    "a.tar (3).gz",
+		},
+		{
+			name:        "rename without extension",
+			policy:      ConflictRename,
+			existing:    []string{"Makefile"},
+			destination: "Makefile",
+			wantPath:    "Makefile (1)",
+		},
+		{
+			name:        "rename hidden file",
+			policy:      ConflictRename,
+			existing:    []string{".profile"},
+			destination: ".profile",
+			wantPath:    ".profile (1)",
+		},
+		{
+			name:        "newer source overwrites",
+			policy:      ConflictNewer,
+			destination: "a.txt",
+			source:      types.Entry{ModTime: newer},
+			dest:        types.Entry{ModTime: older},
+			wantPath:    "a.txt",
+		},
+		{
+			name:        "older source is skipped",
+			policy:      ConflictNewer,
+			destination: "a.txt",
+			source:      types.Entry{ModTime: older},
+			dest:        types.Entry{ModTime: newer},
+			wantPath:    "a.txt",
+			wantSkip:    true,
+		},
+		{
+			name:        "same time is skipped",
+			policy:      ConflictNewer,
+			destination: "a.txt",
+			source:      types.Entry{ModTime: older},
+			dest:        types.Entry{ModTime: older},
+			wantPath:    "a.txt",
+			wantSkip:    true,
+		},
+		{
+			name:        "different size overwrites",
+			policy:      ConflictSizeDiffers,
+			destination: "a.txt",
+			source:      types.Entry{Size: 2},
+			dest:        types.Entry{Size: 1},
+			wantPath:    "a.txt",
+		},
+		{
+			name:        "same size is skipped",
+			policy:      ConflictSizeDiffers,
+			destination: "a.txt",
+			source:      types.Entry{Size: 2, ModTime: newer},
+			dest:        types.Entry{Size: 2, ModTime: older},
+			wantPath:    "a.txt",
+			wantSkip:    true,
+		},
+		{
+			name:        "resume shorter destination",
+			policy:      ConflictResume,
+			destination: "a.txt",
+			source:      types.Entry{Size: 100},
+			dest:        types.Entry{Size: 40},
+			wantPath:    "a.txt",
+			wantOffset:  40,
+		},
+		{
+			name:        "resume complete destination is skipped",
+			policy:      ConflictResume,
+			destination: "a.txt",
+			source:      types.Entry{Size: 100},
+			dest:        types.Entry{Size: 100},
+			wantPath:    "a.txt",
+			wantOffset:  100,
+			wantSkip:    true,
+		},
+		{
+			name:        "resume larger destination overwrites",
+			policy:      ConflictResume,
+			destination: "a.txt",
+			source:      types.Entry{Size: 40},
+			dest:        types.Entry{Size: 100},
+			wantPath:    "a.txt",
+		},
+		{
+			name:        "ask has to be resolved before",
+			policy:      ConflictAsk,
+			destination: "a.txt",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.ToSlash(t.TempDir())
+			for _, name := range tt.existing {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			conflict := Conflict{
+				Source:          tt.source,
+				DestinationPath: path.Join(dir, tt.destination),
+				Destination:     tt.dest,
+			}
+			gotPath, gotOffset, gotSkip, err := resolveConflict(LocalFS{}, tt.policy, conflict)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveConflict() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			wantPath := ""
+			if tt.wantPath != "" {
+				wantPath = path.Join(dir, tt.wantPath)
+			}
+			if gotPath != wantPath || gotOffset != tt.wantOffset || gotSkip != tt.wantSkip {
+				t.Errorf(
+					"resolveConflict() = %q, %d, %v, want %q, %d, %v",
+					gotPath, gotOffset, gotSkip, wantPath, tt.wantOffset, tt.wantSkip,
+				)
+			}
+		})
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	for _, policy := range ConflictPolicies {
+		got, err := ParseConflictPolicy(policy.String())
+		if err != nil || got != policy {
+			t.Errorf("ParseConflictPolicy(%q) = %q, %v, want %q", policy.String(), got, err, policy)
+		}
+	}
+	if _, err := ParseConflictPolicy("sometimes"); err == nil {
+		t.Error("ParseConflictPolicy(\"sometimes\") succeeded")
+	}
+}
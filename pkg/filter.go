@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// FilterMode is how filter pattern matches names, all modes ignore case
+type FilterMode int
+
+const (
+	FilterSubstring FilterMode = iota
+	FilterGlob
+	// FilterFuzzy matches names containing the pattern characters in order
+	FilterFuzzy
+)
+
+func (m FilterMode) String() string {
+	switch m {
+	case FilterGlob:
+		return "glob"
+	case FilterFuzzy:
+		return "fuzzy"
+	default:
+		return "substring"
+	}
+}
+
+// Next cycles the modes
+func (m FilterMode) Next() FilterMode {
+	return (m + 1) % (FilterFuzzy + 1)
+}
+
+// Filter narrows entries to names matching the pattern, empty pattern matches all
+type Filter struct {
+	Mode    FilterMode
+	Pattern string
+}
+
+func (f Filter) Active() bool {
+	return f.Pattern != ""
+}
+
+func (f Filter) Match(name string) bool {
+	if f.Pattern == "" {
+		return true
+	}
+	pattern, name := strings.ToLower(f.Pattern), strings.ToLower(name)
+	switch f.Mode {
+	case FilterGlob:
+		// invalid pattern matches nothing while it is being typed
+		matched, _ := path.Match(pattern, name)
+		return matched
+	case FilterFuzzy:
+		rest := []rune(pattern)
+		for _, r := range name {
+			if len(rest) > 0 && r == rest[0] {
+				rest = rest[1:]
+			}
+		}
+		return len(rest) == 0
+	default:
+		return strings.Contains(name, pattern)
+	}
+}
+
+// ParseNamePattern returns matcher of names, pattern between slashes is regular expression, otherwise glob ignoring case
+func ParseNamePattern(pattern string) (func(name string) bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return Filter{Mode: FilterGlob, Pattern: pattern}.Match, nil
+}
@@ -0,0 +1,141 @@
+package pkg
+
+import "testing"
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		match  []string
+		skip   []string
+	}{
+		{
+			name:   "empty pattern",
+			filter: Filter{Mode: FilterGlob},
+			match:  []string{"a", ""},
+		},
+		{
+			name:   "substring ignores case",
+			filter: Filter{Pattern: "Rep"},
+			match:  []string{"report.pdf", "PREPARE", "rep"},
+			skip:   []string{"re-p", "pdf"},
+		},
+		{
+			name:   "glob",
+			filter: Filter{Mode: FilterGlob, Pattern: "*.TXT"},
+			match:  []string{"a.txt", ".txt", "B.Txt"},
+			skip:   []string{"a.txt.bak", "txt"},
+		},
+		{
+			name:   "glob classes",
+			filter: Filter{Mode: FilterGlob, Pattern: "img_[0-9]?.jpg"},
+			match:  []string{"img_12.jpg", "IMG_0a.JPG"},
+			skip:   []string{"img_a1.jpg", "img_1.jpg"},
+		},
+		{
+			name:   "invalid glob matches nothing",
+			filter: Filter{Mode: FilterGlob, Pattern: "[a"},
+			skip:   []string{"a", "[a"},
+		},
+		{
+			name:   "fuzzy in order",
+			filter: Filter{Mode: FilterFuzzy, Pattern: "rpt"},
+			match:  []string{"report", "RePorT.txt", "rpt"},
+			skip:   []string{"tpr", "rp"},
+		},
+		{
+			name:   "fuzzy repeated characters",
+			filter: Filter{Mode: FilterFuzzy, Pattern: "aa"},
+			match:  []string{"banana", "aa"},
+			skip:   []string{"a", "abc"},
+		},
+		{
+			name:   "fuzzy unicode",
+			filter: Filter{Mode: FilterFuzzy, Pattern: "žl"},
+			match:  []string{"Žlutý kůň", "žába.log"},
+			skip:   []string{"zl"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range tt.match {
+				if !tt.filter.Match(name) {
+					t.Errorf("%v does not match %q", tt.filter, name)
+				}
+			}
+			for _, name := range tt.skip {
+				if tt.filter.Match(name) {
+					t.Errorf("%v matches %q", tt.filter, name)
+				}
+			}
+		})
+	}
+}
+
+func TestParseNamePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		match   []string
+		skip    []string
+		wantErr bool
+	}{
+		{
+			name:    "glob ignores case",
+			pattern: "*.LOG",
+			match:   []string{"a.log", "B.Log"},
+			skip:    []string{"a.log.1"},
+		},
+		{
+			name:    "glob without wildcards is whole name",
+			pattern: "readme",
+			match:   []string{"README"},
+			skip:    []string{"readme.md"},
+		},
+		{
+			name:    "regexp between slashes",
+			pattern: "/^a\\d+$/",
+			match:   []string{"a1", "a123"},
+			skip:    []string{"A1", "ba1", "a1b"},
+		},
+		{
+			name:    "regexp is unanchored",
+			pattern: "/tmp/",
+			match:   []string{"x.tmp.y"},
+			skip:    []string{"TMP"},
+		},
+		{
+			name:    "single slash is glob",
+			pattern: "/",
+			skip:    []string{"/a", "a"},
+		},
+		{
+			name:    "invalid regexp",
+			pattern: "/(/",
+			wantErr: true,
+		},
+		{
+			name:    "invalid glob",
+			pattern: "[a",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := ParseNamePattern(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNamePattern(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+			for _, name := range tt.match {
+				if !match(name) {
+					t.Errorf("%q does not match %q", tt.pattern, name)
+				}
+			}
+			for _, name := range tt.skip {
+				if match(name) {
+					t.Errorf("%q matches %q", tt.pattern, name)
+				}
+			}
+		})
+	}
+}
@@ -1,176 +1,439 @@
 package pkg
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
-	"io/fs"
+	"net"
 	"net/textproto"
 	"os"
 	"path"
-	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jlaffaye/ftp"
 	"github.com/prathoss/goftp/types"
 )
 
-func PrepareDownloadFn(client *ftp.ServerConn) func(string, []types.Entry, string) error {
-	return func(root string, entries []types.Entry, destination string) error {
-		for _, entry := range entries {
-			if entry.Type == types.TypeDirectory {
-				if err := downloadFolderWithContents(client, root, destination, entry); err != nil {
-					return err
-				}
-				continue
-			}
-			if err := downloadFile(client, path.Join(root, entry.Name), path.Join(destination, entry.Name)); err != nil {
-				return err
-			}
+// DataMode is how ftp data connections are opened
+type DataMode string
+
+const (
+	// DataModePassive uses EPSV when the server supports it and PASV otherwise
+	DataModePassive DataMode = ""
+	// DataModePASV uses only PASV, for servers behind NAT announcing broken EPSV
+	DataModePASV DataMode = "pasv"
+	// DataModeActive lets the server connect to the client by PORT or EPRT, for servers with firewalled passive ports
+	DataModeActive DataMode = "active"
+)
+
+// DataModes lists modes in the order they are offered on the login screen
+var DataModes = []DataMode{DataModePassive, DataModePASV, DataModeActive}
+
+func (m DataMode) String() string {
+	if m == DataModePassive {
+		return "passive"
+	}
+	return string(m)
+}
+
+func ParseDataMode(s string) (DataMode, error) {
+	for _, mode := range DataModes {
+		if mode.String() == s || string(mode) == s {
+			return mode, nil
 		}
-		return nil
 	}
+	return DataModePassive, fmt.Errorf("unknown data connection mode %q", s)
 }
 
-func downloadFolderWithContents(client *ftp.ServerConn, root, destination string, entry types.Entry) error {
-	if err := createDirIfNotExist(path.Join(destination, entry.Name)); err != nil {
-		return err
+// TransferType is ftp representation type of transferred files
+type TransferType string
+
+const (
+	TransferTypeBinary TransferType = ""
+	// TransferTypeASCII lets the server convert line endings, resumed files may be corrupted
+	TransferTypeASCII TransferType = "ascii"
+)
+
+// TransferTypes lists types in the order they are offered on the login screen
+var TransferTypes = []TransferType{TransferTypeBinary, TransferTypeASCII}
+
+func (t TransferType) String() string {
+	if t == TransferTypeBinary {
+		return "binary"
 	}
-	walker := client.Walk(path.Join(root, entry.Name))
-	for walker.Next() {
-		// get relative path => walk prints absolute path when called with absolute
-		rel, err := filepath.Rel(root, walker.Path())
-		if err != nil {
-			return err
+	return string(t)
+}
+
+func ParseTransferType(s string) (TransferType, error) {
+	for _, transferType := range TransferTypes {
+		if transferType.String() == s || string(transferType) == s {
+			return transferType, nil
+		}
+	}
+	return TransferTypeBinary, fmt.Errorf("unknown transfer type %q", s)
+}
+
+// FtpFS is RemoteFS over ftp connection, the connection handles single command at a time,
+// so access is serialized, opened files hold the connection until closed
+type FtpFS struct {
+	mu      sync.Mutex
+	client  *ftp.ServerConn
+	control *ftpControl
+	// listing receives what data connections read while List runs
+	listing *strings.Builder
+	// aborting is set while failed upload is closed, its data connection is reset instead of closed,
+	// so the server does not take the partial file as complete
+	aborting int32
+}
+
+func DialFtp(conf ServerConf, password string) (*FtpFS, error) {
+	var tlsConfig *tls.Config
+	if conf.TLS.Mode != TLSModeNone {
+		var err error
+		if tlsConfig, err = conf.TLS.Config(conf.Server); err != nil {
+			return nil, err
+		}
+	}
+	f := &FtpFS{}
+	options := []ftp.DialOption{
+		ftp.DialWithTimeout(conf.DialTimeout()),
+		// active mode replaces the passive one, which the library always requests, so the cheaper PASV is used
+		ftp.DialWithDisabledEPSV(conf.DataMode != DataModePassive),
+		ftp.DialWithDialFunc(f.dialFunc(conf, tlsConfig)),
+	}
+	switch conf.TLS.Mode {
+	case TLSModeNone:
+	case TLSModeExplicit, TLSModeImplicit:
+		// the connections are secured when dialed, the library only turns on protection of data connections
+		options = append(options, ftp.DialWithTLS(tlsConfig))
+	default:
+		return nil, fmt.Errorf("unknown tls mode %q", conf.TLS.Mode)
+	}
+	c, err := ftp.Dial(conf.DialAddress(), options...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Login(conf.User, password); err != nil {
+		_ = c.Quit()
+		return nil, err
+	}
+	// login switches to binary
+	if conf.TransferType == TransferTypeASCII {
+		if err := c.Type(ftp.TransferTypeASCII); err != nil {
+			_ = c.Quit()
+			return nil, err
 		}
-		destinationAbs := path.Join(destination, rel)
-		if walker.Stat().Type == ftp.EntryTypeFolder {
-			if err := createDirIfNotExist(destinationAbs); err != nil {
-				return err
+	}
+	f.client = c
+	return f, nil
+}
+
+// dialFunc dials the control connection first and data connections after it, the client library
+// does not secure connections returned by the function
+func (f *FtpFS) dialFunc(conf ServerConf, tlsConfig *tls.Config) func(network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: conf.DialTimeout()}
+	return func(network, address string) (net.Conn, error) {
+		if f.control == nil {
+			conn, err := dialControl(dialer, address, conf.TLS.Mode, tlsConfig)
+			if err != nil {
+				return nil, err
 			}
+			f.control = newFtpControl(conn, conf.DialTimeout())
+			return conn, nil
+		}
+		var raw net.Conn
+		var err error
+		if conf.DataMode == DataModeActive {
+			raw, err = f.control.listenActive()
+		} else {
+			raw, err = dialer.Dial(network, address)
+		}
+		if err != nil {
+			return nil, err
+		}
+		dataConn := &ftpDataConn{Conn: raw, raw: raw, timeout: conf.DialTimeout(), aborting: &f.aborting}
+		if f.listing != nil {
+			dataConn.capture = f.listing
+		}
+		if tlsConfig != nil {
+			// the handshake is done on first use, some servers start it only after the transfer command is sent
+			dataConn.tls = tls.Client(raw, tlsConfig)
+			dataConn.Conn = dataConn.tls
+		}
+		return dataConn, nil
+	}
+}
+
+// ftpDataConn is reset when closed while the upload is aborted, the server sees failed transfer instead of the end of the file
+type ftpDataConn struct {
+	net.Conn
+	// raw is below tls, which would send close notify
+	raw      net.Conn
+	tls      *tls.Conn
+	timeout  time.Duration
+	aborting *int32
+	// capture gets copy of the read data
+	capture io.Writer
+}
+
+func (c *ftpDataConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if c.capture != nil {
+		_, _ = c.capture.Write(p[:n])
+	}
+	return n, err
+}
+
+func (c *ftpDataConn) Close() error {
+	if atomic.LoadInt32(c.aborting) == 0 {
+		if c.tls != nil {
+			// empty upload is closed before anything started the handshake
+			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+			_ = c.tls.HandshakeContext(ctx)
+			cancel()
+		}
+		return c.Conn.Close()
+	}
+	if raw, ok := c.raw.(interface{ SetLinger(sec int) error }); ok {
+		_ = raw.SetLinger(0)
+	}
+	return c.raw.Close()
+}
+
+func (f *FtpFS) List(location string) ([]types.Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// the client library does not keep permissions and owners, they are parsed from the raw listing
+	var listing strings.Builder
+	f.listing = &listing
+	files, err := f.client.List(location)
+	f.listing = nil
+	if err != nil {
+		return nil, err
+	}
+	attrs := parseFtpListing(listing.String())
+	entries := make([]types.Entry, 0, len(files))
+	for _, file := range files {
+		// some servers list the directory itself and its parent
+		if file.Name == "." || file.Name == ".." {
 			continue
 		}
-		if err := downloadFile(client, walker.Path(), destinationAbs); err != nil {
-			return err
+		entry := FtpToEntry(file)
+		if attr, ok := attrs[file.Name]; ok {
+			entry.Mode, entry.Owner, entry.Group = attr.mode, attr.owner, attr.group
 		}
+		entries = append(entries, entry)
 	}
-	return nil
+	return entries, nil
 }
 
-func downloadFile(c *ftp.ServerConn, source, destination string) error {
-	result, err := c.Retr(source)
+// Stat looks the entry up in the listing of its parent, ftp has no portable command for single entry
+func (f *FtpFS) Stat(location string) (types.Entry, error) {
+	if location == "/" {
+		return types.Entry{Name: "/", Type: types.TypeDirectory}, nil
+	}
+	entries, err := f.List(path.Dir(location))
 	if err != nil {
-		return err
+		return types.Entry{}, err
 	}
-	defer result.Close()
-	fl, err := os.OpenFile(destination, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0755)
+	name := path.Base(location)
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry, nil
+		}
+	}
+	return types.Entry{}, notExistError("stat", location)
+}
+
+func (f *FtpFS) Open(location string) (io.ReadCloser, error) {
+	return f.OpenFrom(location, 0)
+}
+
+// OpenFrom uses REST to skip the offset
+func (f *FtpFS) OpenFrom(location string, offset uint64) (io.ReadCloser, error) {
+	f.mu.Lock()
+	r, err := f.client.RetrFrom(location, offset)
 	if err != nil {
-		return err
+		f.mu.Unlock()
+		return nil, err
 	}
-	defer fl.Close()
-	if _, err := fl.Seek(0, 0); err != nil {
-		return err
+	return &ftpReader{Response: r, unlock: f.mu.Unlock}, nil
+}
+
+// errReadAborted is returned by Close of file which was not read to the end, the server may still reply
+// to the aborted transfer, so the connection must not be used for other commands
+var errReadAborted = errors.New("file closed before it was read to the end")
+
+type ftpReader struct {
+	*ftp.Response
+	unlock func()
+	once   sync.Once
+	eof    bool
+}
+
+func (r *ftpReader) Read(p []byte) (int, error) {
+	n, err := r.Response.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.eof = true
 	}
-	if _, err := io.Copy(fl, result); err != nil {
+	return n, err
+}
+
+func (r *ftpReader) Close() error {
+	err := r.Response.Close()
+	r.once.Do(r.unlock)
+	if r.eof {
 		return err
 	}
-	return nil
+	if err != nil {
+		return fmt.Errorf("%w: %s", errReadAborted, err.Error())
+	}
+	return errReadAborted
 }
 
-func PrepareUploadFn(client *ftp.ServerConn) func(string, []types.Entry, string) error {
-	return func(root string, entries []types.Entry, destination string) error {
-		for _, entry := range entries {
-			// func to properly handle defer
-			if entry.Type == types.TypeDirectory {
-				if err := uploadDirWithContent(client, root, destination, entry); err != nil {
-					return err
-				}
-				continue
-			}
-			if err := uploadFile(client, path.Join(root, entry.Name), path.Join(destination, entry.Name)); err != nil {
-				return err
-			}
+// Create returns writer which streams into STOR running in background, the upload result is returned by Close
+func (f *FtpFS) Create(location string) (io.WriteCloser, error) {
+	return f.WriteFrom(location, 0)
+}
+
+// WriteFrom uses REST before STOR to continue at the offset, servers refusing REST for uploads get APPE,
+// which continues at the end of the file, that is the offset when resuming
+func (f *FtpFS) WriteFrom(location string, offset uint64) (io.WriteCloser, error) {
+	f.mu.Lock()
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			atomic.StoreInt32(&f.aborting, 0)
+			f.mu.Unlock()
+		}()
+		err := f.client.StorFrom(location, pr, offset)
+		if offset > 0 && isNotImplemented(err) {
+			err = f.client.Append(location, pr)
 		}
-		return nil
-	}
+		// unblock writer when the server refuses the upload
+		_ = pr.CloseWithError(err)
+		done <- err
+	}()
+	return &ftpWriter{PipeWriter: pw, done: done, aborting: &f.aborting}, nil
 }
 
-func uploadDirWithContent(client *ftp.ServerConn, root, destination string, entry types.Entry) error {
-	return filepath.Walk(
-		path.Join(root, entry.Name),
-		func(walkPath string, info fs.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			rel, err := filepath.Rel(root, walkPath)
-			if err != nil {
-				return err
-			}
-			destinationAbs := path.Join(destination, rel)
-			if info.IsDir() {
-				if err := client.MakeDir(destinationAbs); !isErrorDirExists(err) {
-					return err
-				}
-				return nil
-			}
-			// walkPath will be absolute => remove root to make destination
-			return uploadFile(client, walkPath, destinationAbs)
-		})
+type ftpWriter struct {
+	*io.PipeWriter
+	done     <-chan error
+	aborting *int32
+}
+
+func (w *ftpWriter) Close() error {
+	_ = w.PipeWriter.Close()
+	return <-w.done
 }
 
-func isErrorDirExists(err error) bool {
+// CloseWithError aborts the upload, the part sent before stays on the server, but it is not finished as complete
+func (w *ftpWriter) CloseWithError(err error) error {
+	atomic.StoreInt32(w.aborting, 1)
+	_ = w.PipeWriter.CloseWithError(err)
+	return <-w.done
+}
+
+// isNotImplemented reports whether the server rejected the command as unknown,
+// such rejection comes before any data is sent
+func isNotImplemented(err error) bool {
 	var tpErr *textproto.Error
-	return errors.As(err, &tpErr) && tpErr.Code == ftp.StatusFileUnavailable
+	if !errors.As(err, &tpErr) {
+		return false
+	}
+	switch tpErr.Code {
+	case ftp.StatusBadCommand, ftp.StatusBadArguments, ftp.StatusNotImplemented, ftp.StatusNotImplementedParameter:
+		return true
+	}
+	return false
 }
 
-func uploadFile(c *ftp.ServerConn, source, destination string) error {
-	fl, err := os.Open(source)
+func (f *FtpFS) Mkdir(location string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.client.MakeDir(location)
+}
+
+func (f *FtpFS) Remove(location string) error {
+	entry, err := f.Stat(location)
 	if err != nil {
 		return err
 	}
-	defer fl.Close()
-	if err := c.Stor(destination, fl); err != nil {
-		return err
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if entry.Type == types.TypeDirectory {
+		return f.client.RemoveDir(location)
+	}
+	return f.client.Delete(location)
+}
+
+func (f *FtpFS) Rename(from, to string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.client.Rename(from, to)
+}
+
+// Chmod uses SITE CHMOD, which is not part of the standard, but most unix servers support it
+func (f *FtpFS) Chmod(location string, mode os.FileMode) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := f.control.command(2, "SITE CHMOD %03o %s", mode.Perm(), location)
+	if isNotImplemented(err) {
+		return fmt.Errorf("ftp chmod %w by the server: %s", ErrNotSupported, err.Error())
 	}
-	return nil
+	return err
 }
 
-func PrepareFtpDeleteFn(client *ftp.ServerConn) func(location string, entries []types.Entry) error {
-	return func(location string, entries []types.Entry) error {
-		for _, entry := range entries {
-			absolutePath := path.Join(location, entry.Name)
-			var delFn func(string) error
-			switch entry.Type {
-			case types.TypeDirectory:
-				delFn = client.RemoveDirRecur
-			default:
-				delFn = client.Delete
+// Mode reads permissions from unix.mode fact of MLST, for entries listed in formats without them
+func (f *FtpFS) Mode(location string) (os.FileMode, error) {
+	f.mu.Lock()
+	message, err := f.control.command(ftp.StatusRequestedFileActionOK, "MLST %s", location)
+	f.mu.Unlock()
+	if isNotImplemented(err) {
+		return 0, fmt.Errorf("ftp mlst %w by the server: %s", ErrNotSupported, err.Error())
+	}
+	if err != nil {
+		return 0, err
+	}
+	// facts are on the line starting with space, the first and the last line are free text
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, " ") {
+			continue
+		}
+		facts := strings.SplitN(strings.TrimSpace(line), " ", 2)[0]
+		for _, fact := range strings.Split(facts, ";") {
+			name, value, ok := strings.Cut(fact, "=")
+			if !ok || !strings.EqualFold(name, "unix.mode") {
+				continue
 			}
-			if err := delFn(absolutePath); err != nil {
-				return err
+			mode, err := strconv.ParseUint(value, 8, 32)
+			if err != nil {
+				return 0, fmt.Errorf("invalid unix.mode %q: %w", value, err)
 			}
+			return os.FileMode(mode).Perm(), nil
 		}
-		return nil
 	}
+	return 0, fmt.Errorf("ftp unix.mode %w by the server", ErrNotSupported)
 }
 
-func KeepFtpAlive(client *ftp.ServerConn) (chan<- struct{}, <-chan error) {
-	ticker := time.NewTimer(15 * time.Second)
-	quit := make(chan struct{})
-	errChan := make(chan error)
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				if err := client.NoOp(); err != nil {
-					errChan <- err
-					return
-				}
-			case <-quit:
-				return
-			}
-		}
-	}()
-	return quit, errChan
+func (f *FtpFS) NoOp() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.client.NoOp()
+}
+
+func (f *FtpFS) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.client.Quit()
 }
 
 func FtpToEntry(f *ftp.Entry) types.Entry {
@@ -184,8 +447,11 @@ func FtpToEntry(f *ftp.Entry) types.Entry {
 		tp = types.TypeDirectory
 	}
 	return types.Entry{
-		Name: f.Name,
-		Type: tp,
-		Size: f.Size,
+		Name:    f.Name,
+		Type:    tp,
+		Size:    f.Size,
+		ModTime: f.Time,
+		// permissions and owner are not kept by the client library, List adds them from the raw listing
+		LinkTarget: f.Target,
 	}
 }
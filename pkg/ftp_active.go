@@ -0,0 +1,136 @@
+package pkg
+
+import (
+	"net"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+)
+
+// activeCloseGrace is how long unused active connection waits for the server when closed, transfers which failed
+// are never connected, servers connect before they confirm the transfer, so empty uploads are connected by then
+const activeCloseGrace = 500 * time.Millisecond
+
+// listenActive asks the server to connect to the client by PORT, or by EPRT over ipv6. The client library
+// requests passive mode before it dials the data connection, the address sent here replaces it.
+func (c *ftpControl) listenActive() (net.Conn, error) {
+	local := c.conn.LocalAddr().(*net.TCPAddr)
+	listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: local.IP})
+	if err != nil {
+		return nil, err
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if ip := local.IP.To4(); ip != nil {
+		_, err = c.command(ftp.StatusCommandOK, "PORT %d,%d,%d,%d,%d,%d", ip[0], ip[1], ip[2], ip[3], port>>8, port&0xff)
+	} else {
+		_, err = c.command(ftp.StatusCommandOK, "EPRT |2|%s|%d|", local.IP, port)
+	}
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	conn := &activeConn{
+		listener: listener,
+		server:   c.conn.RemoteAddr().(*net.TCPAddr),
+		accepted: make(chan struct{}),
+	}
+	go conn.accept(c.timeout)
+	return conn, nil
+}
+
+// activeConn is data connection the server connects to, it is accepted in background,
+// so the transfer command can be sent meanwhile
+type activeConn struct {
+	listener *net.TCPListener
+	server   *net.TCPAddr
+	accepted chan struct{}
+	conn     *net.TCPConn
+	err      error
+}
+
+func (c *activeConn) accept(timeout time.Duration) {
+	defer close(c.accepted)
+	defer c.listener.Close()
+	_ = c.listener.SetDeadline(time.Now().Add(timeout))
+	for {
+		conn, err := c.listener.AcceptTCP()
+		if err != nil {
+			c.err = err
+			return
+		}
+		// the data may be sent or read only by the server
+		if conn.RemoteAddr().(*net.TCPAddr).IP.Equal(c.server.IP) {
+			c.conn = conn
+			return
+		}
+		_ = conn.Close()
+	}
+}
+
+func (c *activeConn) wait() error {
+	<-c.accepted
+	return c.err
+}
+
+func (c *activeConn) Read(p []byte) (int, error) {
+	if err := c.wait(); err != nil {
+		return 0, err
+	}
+	return c.conn.Read(p)
+}
+
+func (c *activeConn) Write(p []byte) (int, error) {
+	if err := c.wait(); err != nil {
+		return 0, err
+	}
+	return c.conn.Write(p)
+}
+
+func (c *activeConn) Close() error {
+	select {
+	case <-c.accepted:
+	case <-time.After(activeCloseGrace):
+		_ = c.listener.Close()
+		<-c.accepted
+	}
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+func (c *activeConn) SetLinger(sec int) error {
+	if err := c.wait(); err != nil {
+		return err
+	}
+	return c.conn.SetLinger(sec)
+}
+
+func (c *activeConn) LocalAddr() net.Addr {
+	return c.listener.Addr()
+}
+
+func (c *activeConn) RemoteAddr() net.Addr {
+	return c.server
+}
+
+func (c *activeConn) SetDeadline(t time.Time) error {
+	if err := c.wait(); err != nil {
+		return err
+	}
+	return c.conn.SetDeadline(t)
+}
+
+func (c *activeConn) SetReadDeadline(t time.Time) error {
+	if err := c.wait(); err != nil {
+		return err
+	}
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *activeConn) SetWriteDeadline(t time.Time) error {
+	if err := c.wait(); err != nil {
+		return err
+	}
+	return c.conn.SetWriteDeadline(t)
+}
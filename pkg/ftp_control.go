@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+)
+
+// ftpControl sends commands the client library does not expose over the control connection of the library,
+// it is used under the lock of FtpFS, so no reply of the library is pending
+type ftpControl struct {
+	conn    net.Conn
+	text    *textproto.Conn
+	timeout time.Duration
+}
+
+func newFtpControl(conn net.Conn, timeout time.Duration) *ftpControl {
+	return &ftpControl{conn: conn, text: textproto.NewConn(conn), timeout: timeout}
+}
+
+// command sends command and reads the reply, expectCode 0 accepts any success code
+func (c *ftpControl) command(expectCode int, format string, args ...interface{}) (string, error) {
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	defer c.conn.SetDeadline(time.Time{})
+	if err := c.text.PrintfLine(format, args...); err != nil {
+		return "", err
+	}
+	code, message, err := c.text.ReadResponse(expectCode)
+	if err == nil && expectCode == 0 && code >= 400 {
+		err = &textproto.Error{Code: code, Msg: message}
+	}
+	return message, err
+}
+
+// dialControl dials the control connection secured by tls when it is configured. Explicit tls is negotiated here
+// instead of by the client library, so commands can be sent over the secured connection, the greeting read
+// before is replayed to the library.
+func dialControl(dialer *net.Dialer, address string, mode TLSMode, tlsConfig *tls.Config) (net.Conn, error) {
+	if mode == TLSModeImplicit {
+		return tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	}
+	raw, err := dialer.Dial("tcp", address)
+	if err != nil || mode != TLSModeExplicit {
+		return raw, err
+	}
+	_ = raw.SetDeadline(time.Now().Add(dialer.Timeout))
+	text := textproto.NewConn(raw)
+	code, greeting, err := text.ReadResponse(ftp.StatusReady)
+	if err == nil {
+		err = text.PrintfLine("AUTH TLS")
+	}
+	if err == nil {
+		_, _, err = text.ReadResponse(ftp.StatusAuthOK)
+	}
+	var conn *tls.Conn
+	if err == nil {
+		conn = tls.Client(raw, tlsConfig)
+		err = conn.Handshake()
+	}
+	if err != nil {
+		_ = raw.Close()
+		return nil, err
+	}
+	_ = raw.SetDeadline(time.Time{})
+	return &greetedConn{
+		Conn:   conn,
+		reader: io.MultiReader(strings.NewReader(fmt.Sprintf("%d %s\r\n", code, strings.ReplaceAll(greeting, "\n", " "))), conn),
+	}, nil
+}
+
+// greetedConn reads the greeting of the server again before the rest of the connection
+type greetedConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c *greetedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ftpListAttrs are parts of listing the client library does not keep
+type ftpListAttrs struct {
+	mode  os.FileMode
+	owner string
+	group string
+}
+
+// parseFtpListing reads permissions, owners and groups from raw MLSD or unix LIST listing by entry name,
+// lines of other formats are skipped
+func parseFtpListing(listing string) map[string]ftpListAttrs {
+	attrs := make(map[string]ftpListAttrs)
+	for _, line := range strings.Split(listing, "\n") {
+		line = strings.TrimRight(line, "\r")
+		name, attr, ok := parseMlsdAttrs(line)
+		if !ok {
+			name, attr, ok = parseLsAttrs(line)
+		}
+		if ok {
+			attrs[name] = attr
+		}
+	}
+	return attrs
+}
+
+// parseMlsdAttrs reads unix facts, names of owner and group are preferred to their ids
+func parseMlsdAttrs(line string) (string, ftpListAttrs, bool) {
+	facts, name, ok := strings.Cut(line, " ")
+	if !ok || !strings.Contains(facts, "=") {
+		return "", ftpListAttrs{}, false
+	}
+	var attr ftpListAttrs
+	var uid, gid string
+	for _, fact := range strings.Split(facts, ";") {
+		key, value, ok := strings.Cut(fact, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "unix.mode":
+			if mode, err := strconv.ParseUint(value, 8, 32); err == nil {
+				attr.mode = os.FileMode(mode).Perm()
+			}
+		case "unix.ownername":
+			attr.owner = value
+		case "unix.groupname":
+			attr.group = value
+		case "unix.owner", "unix.uid":
+			uid = value
+		case "unix.group", "unix.gid":
+			gid = value
+		}
+	}
+	if attr.owner == "" {
+		attr.owner = uid
+	}
+	if attr.group == "" {
+		attr.group = gid
+	}
+	return name, attr, true
+}
+
+// parseLsAttrs reads line in the format of ls -l, the name is the rest after the time
+func parseLsAttrs(line string) (string, ftpListAttrs, bool) {
+	// permissions may be followed by + of acl
+	if i := strings.IndexByte(line, ' '); i != 10 && !(i == 11 && line[10] == '+') {
+		return "", ftpListAttrs{}, false
+	}
+	fields := make([]string, 0, 8)
+	rest := line
+	for len(fields) < 8 {
+		rest = strings.TrimLeft(rest, " ")
+		field, remaining, ok := strings.Cut(rest, " ")
+		if !ok {
+			return "", ftpListAttrs{}, false
+		}
+		fields, rest = append(fields, field), remaining
+	}
+	// some servers list folder or zero links without owner and group
+	if fields[1] == "folder" || fields[1] == "0" {
+		return "", ftpListAttrs{}, false
+	}
+	name := strings.TrimLeft(rest, " ")
+	if fields[0][0] == 'l' {
+		if i := strings.Index(name, " -> "); i > 0 {
+			name = name[:i]
+		}
+	}
+	return name, ftpListAttrs{mode: parseLsMode(fields[0][1:10]), owner: fields[2], group: fields[3]}, true
+}
+
+// parseLsMode converts rwxr-xr-x into permission bits, s and t mean executable when lowercase
+func parseLsMode(perms string) os.FileMode {
+	var mode os.FileMode
+	for i, c := range perms {
+		if c != '-' && c != 'S' && c != 'T' {
+			mode |= 1 << (8 - i)
+		}
+	}
+	return mode
+}
@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFtpListing(t *testing.T) {
+	tests := []struct {
+		name    string
+		listing string
+		want    map[string]ftpListAttrs
+	}{
+		{
+			name: "vsftpd list",
+			listing: "drwxr-xr-x    2 1000     1000         4096 Mar 03 09:12 docs\r\n" +
+				"-rw-r--r--    1 1000     1000       120410 Jan 17  2021 report 2020.pdf\r\n" +
+				"lrwxrwxrwx    1 0        0               7 Oct 02 11:50 latest -> docs/v2\r\n",
+			want: map[string]ftpListAttrs{
+				"docs":            {mode: 0755, owner: "1000", group: "1000"},
+				"report 2020.pdf": {mode: 0644, owner: "1000", group: "1000"},
+				"latest":          {mode: 0777, owner: "0", group: "0"},
+			},
+		},
+		{
+			name: "proftpd list with total and acl",
+			listing: "total 16\r\n" +
+				"drwxrws---+  3 www-data developers  4096 Sep 12 14:02 site\r\n" +
+				"-rwsr-xr-T   1 root     root       10240 Feb 29  2020 setuid\r\n" +
+				"-rwSr-----   1 root     root           0 Feb 29 23:59 not executable\r\n",
+			want: map[string]ftpListAttrs{
+				"site":           {mode: 0770, owner: "www-data", group: "developers"},
+				"setuid":         {mode: 0754, owner: "root", group: "root"},
+				"not executable": {mode: 0640, owner: "root", group: "root"},
+			},
+		},
+		{
+			name:    "symlink name containing arrow",
+			listing: "lrwxrwxrwx 1 ftp ftp 12 Jun 01 08:00 a -> b -> c\n",
+			want: map[string]ftpListAttrs{
+				"a": {mode: 0777, owner: "ftp", group: "ftp"},
+			},
+		},
+		{
+			name: "pure-ftpd mlsd",
+			listing: "type=cdir;sizd=4096;modify=20240301091200;UNIX.mode=0755;UNIX.uid=1000;UNIX.gid=1000;unique=803g2; .\r\n" +
+				"type=file;size=120410;modify=20210117000000;UNIX.mode=0644;UNIX.uid=1000;UNIX.gid=100;unique=803g5; report 2020.pdf\r\n",
+			want: map[string]ftpListAttrs{
+				".":               {mode: 0755, owner: "1000", group: "1000"},
+				"report 2020.pdf": {mode: 0644, owner: "1000", group: "100"},
+			},
+		},
+		{
+			name: "proftpd mlsd prefers names to ids",
+			listing: "modify=20240912140200;perm=flcdmpe;type=dir;unique=FD00U2F0002;UNIX.group=33;UNIX.groupname=developers;" +
+				"UNIX.mode=02770;UNIX.owner=33;UNIX.ownername=www-data; site\r\n",
+			want: map[string]ftpListAttrs{
+				"site": {mode: 0770, owner: "www-data", group: "developers"},
+			},
+		},
+		{
+			name:    "mlsd without unix facts",
+			listing: "type=file;size=3;modify=20240101000000; a.txt\r\n",
+			want: map[string]ftpListAttrs{
+				"a.txt": {},
+			},
+		},
+		{
+			name: "formats without owners are skipped",
+			listing: "03-03-24  09:12AM       <DIR>          docs\r\n" +
+				"01-17-21  12:00AM               120410 report.pdf\r\n" +
+				"drwxrwxrwx   folder        0 Mar 03 09:12 mac\r\n" +
+				"-rw-r--r--   0        0      120 Mar 03 09:12 nolinks\r\n" +
+				"-rw-r--r-- 1 short\r\n",
+			want: map[string]ftpListAttrs{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseFtpListing(tt.listing); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFtpListing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
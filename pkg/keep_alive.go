@@ -0,0 +1,26 @@
+package pkg
+
+import "time"
+
+// KeepAlive calls noOp periodically until quit, the first failure is sent to the error channel
+func KeepAlive(noOp func() error) (chan<- struct{}, <-chan error) {
+	ticker := time.NewTicker(15 * time.Second)
+	// buffered so quitting does not block when the loop already ended with an error
+	quit := make(chan struct{}, 1)
+	errChan := make(chan error)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := noOp(); err != nil {
+					errChan <- err
+					return
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return quit, errChan
+}
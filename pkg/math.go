@@ -7,8 +7,21 @@ func Min(items ...int) int {
 	min := items[0]
 	for i := 1; i < len(items); i++ {
 		if items[i] < min {
-			min = items[1]
+			min = items[i]
 		}
 	}
 	return min
 }
+
+func Max(items ...int) int {
+	if len(items) == 0 {
+		panic("no items to get maximum from")
+	}
+	max := items[0]
+	for i := 1; i < len(items); i++ {
+		if items[i] > max {
+			max = items[i]
+		}
+	}
+	return max
+}
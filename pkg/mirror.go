@@ -0,0 +1,197 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/prathoss/goftp/types"
+)
+
+type MirrorAction int
+
+const (
+	// MirrorCopy copies entry missing at the destination, directories with their content
+	MirrorCopy MirrorAction = iota
+	// MirrorUpdate overwrites file which differs
+	MirrorUpdate
+	// MirrorDelete removes entry missing at the source
+	MirrorDelete
+	// MirrorSkip is entry which can not be mirrored without deleting, it is not executed
+	MirrorSkip
+)
+
+func (a MirrorAction) String() string {
+	switch a {
+	case MirrorCopy:
+		return "copy"
+	case MirrorUpdate:
+		return "update"
+	case MirrorDelete:
+		return "delete"
+	default:
+		return "skip"
+	}
+}
+
+// mirrorTimeTolerance covers servers listing modification time rounded to minutes
+const mirrorTimeTolerance = time.Minute
+
+type MirrorOptions struct {
+	// DeleteExtraneous removes entries at the destination which are not at the source
+	DeleteExtraneous bool
+}
+
+// MirrorStep is single action of a mirror plan
+type MirrorStep struct {
+	Action MirrorAction
+	// Path is relative to the mirrored directories
+	Path string
+	// Entry is the source entry, for delete the destination one
+	Entry types.Entry
+}
+
+// MirrorPlan lists what has to be done to make destination directory same as the source one.
+// Steps are in the order they are executed, extraneous entries are deleted after everything was copied.
+type MirrorPlan struct {
+	Source          RemoteFS
+	SourceRoot      string
+	Destination     RemoteFS
+	DestinationRoot string
+	Steps           []MirrorStep
+	// extraneous are deletes of entries missing at the source, appended to the steps once compared
+	extraneous []MirrorStep
+}
+
+// PlanMirror compares source and destination trees, file is updated when the size differs or the source is newer
+func PlanMirror(source RemoteFS, sourceRoot string, destination RemoteFS, destinationRoot string, opts MirrorOptions) (MirrorPlan, error) {
+	plan := MirrorPlan{
+		Source:          source,
+		SourceRoot:      sourceRoot,
+		Destination:     destination,
+		DestinationRoot: destinationRoot,
+	}
+	if err := plan.compare("", opts); err != nil {
+		return MirrorPlan{}, err
+	}
+	plan.Steps = append(plan.Steps, plan.extraneous...)
+	plan.extraneous = nil
+	return plan, nil
+}
+
+func (p *MirrorPlan) compare(relative string, opts MirrorOptions) error {
+	sourceEntries, err := p.Source.List(path.Join(p.SourceRoot, relative))
+	if err != nil {
+		return err
+	}
+	destinationEntries, err := p.Destination.List(path.Join(p.DestinationRoot, relative))
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]types.Entry, len(destinationEntries))
+	for _, entry := range destinationEntries {
+		existing[entry.Name] = entry
+	}
+	for _, entry := range sourceEntries {
+		location := path.Join(relative, entry.Name)
+		destinationEntry, ok := existing[entry.Name]
+		delete(existing, entry.Name)
+		isDir, destinationIsDir := entry.Type == types.TypeDirectory, destinationEntry.Type == types.TypeDirectory
+		switch {
+		case !ok:
+			p.add(MirrorCopy, location, entry)
+		case isDir != destinationIsDir && opts.DeleteExtraneous:
+			// the entry of other type is replaced right away
+			p.add(MirrorDelete, location, destinationEntry)
+			p.add(MirrorCopy, location, entry)
+		case isDir != destinationIsDir:
+			p.add(MirrorSkip, location, entry)
+		case isDir:
+			if err := p.compare(location, opts); err != nil {
+				return err
+			}
+		case mirrorDiffers(entry, destinationEntry):
+			p.add(MirrorUpdate, location, entry)
+		}
+	}
+	if !opts.DeleteExtraneous {
+		return nil
+	}
+	// iterate listing to keep the order stable
+	for _, entry := range destinationEntries {
+		if _, extraneous := existing[entry.Name]; extraneous {
+			p.extraneous = append(p.extraneous, MirrorStep{Action: MirrorDelete, Path: path.Join(relative, entry.Name), Entry: entry})
+		}
+	}
+	return nil
+}
+
+func (p *MirrorPlan) add(action MirrorAction, location string, entry types.Entry) {
+	p.Steps = append(p.Steps, MirrorStep{Action: action, Path: location, Entry: entry})
+}
+
+func mirrorDiffers(source, destination types.Entry) bool {
+	return source.Size != destination.Size || source.ModTime.Sub(destination.ModTime) > mirrorTimeTolerance
+}
+
+// Count returns number of steps with the action
+func (p MirrorPlan) Count(action MirrorAction) int {
+	count := 0
+	for _, step := range p.Steps {
+		if step.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Execute runs the steps one by one, onStep is called before each step and may be nil
+func (p MirrorPlan) Execute(ctx context.Context, onStep func(MirrorStep)) error {
+	for _, step := range p.Steps {
+		if step.Action == MirrorSkip {
+			continue
+		}
+		if onStep != nil {
+			onStep(step)
+		}
+		var err error
+		if step.Action == MirrorDelete {
+			err = RemoveAll(p.Destination, path.Join(p.DestinationRoot, path.Dir(step.Path)), []types.Entry{step.Entry})
+		} else {
+			err = CopyWithProgress(
+				ctx,
+				p.Source,
+				path.Join(p.SourceRoot, path.Dir(step.Path)),
+				[]types.Entry{step.Entry},
+				p.Destination,
+				path.Join(p.DestinationRoot, path.Dir(step.Path)),
+				TransferOptions{Conflict: ConflictOverwrite},
+				nil,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", step.Action, step.Path, err)
+		}
+	}
+	return nil
+}
+
+// Enqueue adds job for each step to the queue, deletes included, so nothing is changed before the queue runs them
+func (p MirrorPlan) Enqueue(queue *TransferQueue) {
+	for _, step := range p.Steps {
+		switch step.Action {
+		case MirrorDelete:
+			queue.AddRemove(p.Destination, path.Join(p.DestinationRoot, path.Dir(step.Path)), []types.Entry{step.Entry})
+		case MirrorCopy, MirrorUpdate:
+			queue.Add(
+				p.Source,
+				path.Join(p.SourceRoot, path.Dir(step.Path)),
+				[]types.Entry{step.Entry},
+				p.Destination,
+				path.Join(p.DestinationRoot, path.Dir(step.Path)),
+				TransferOptions{Conflict: ConflictOverwrite},
+			)
+		}
+	}
+}
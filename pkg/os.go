@@ -2,27 +2,116 @@ package pkg
 
 import (
 	"errors"
+	"io"
 	"io/fs"
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/prathoss/goftp/types"
 )
 
-func OsDeleteFn(location string, entries []types.Entry) error {
-	for _, entry := range entries {
-		absolutePath := path.Join(location, entry.Name)
-		var delFn func(string) error
-		switch entry.Type {
-		case types.TypeDirectory:
-			delFn = os.RemoveAll
-		default:
-			delFn = os.Remove
+// LocalFS is RemoteFS over the local disk
+type LocalFS struct{}
+
+func (LocalFS) List(location string) ([]types.Entry, error) {
+	files, err := os.ReadDir(location)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]types.Entry, 0, len(files))
+	for _, file := range files {
+		entry, err := OsToEntry(file)
+		if err != nil {
+			// entry was removed meanwhile
+			continue
 		}
-		if err := delFn(absolutePath); err != nil {
-			return err
+		if entry.Type == types.TypeLink {
+			entry.LinkTarget, _ = os.Readlink(filepath.Join(location, entry.Name))
 		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (LocalFS) Stat(location string) (types.Entry, error) {
+	info, err := os.Lstat(location)
+	if err != nil {
+		return types.Entry{}, err
+	}
+	entry := FileInfoToEntry(info)
+	if entry.Type == types.TypeLink {
+		entry.LinkTarget, _ = os.Readlink(location)
+	}
+	return entry, nil
+}
+
+func (LocalFS) Open(location string) (io.ReadCloser, error) {
+	return os.Open(location)
+}
+
+func (LocalFS) OpenFrom(location string, offset uint64) (io.ReadCloser, error) {
+	f, err := os.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (LocalFS) Create(location string) (io.WriteCloser, error) {
+	return os.OpenFile(location, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+}
+
+func (LocalFS) WriteFrom(location string, offset uint64) (io.WriteCloser, error) {
+	f, err := os.OpenFile(location, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// drop anything past the offset so the result does not keep stale tail
+	if err := f.Truncate(int64(offset)); err != nil {
+		_ = f.Close()
+		return nil, err
 	}
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (LocalFS) Mkdir(location string) error {
+	return os.Mkdir(location, 0750)
+}
+
+func (LocalFS) Remove(location string) error {
+	return os.Remove(location)
+}
+
+func (LocalFS) Rename(from, to string) error {
+	return os.Rename(from, to)
+}
+
+func (LocalFS) Chmod(location string, mode os.FileMode) error {
+	return os.Chmod(location, mode)
+}
+
+// Mode is used for entries listed without permission bits, which is also the case of mode 000
+func (LocalFS) Mode(location string) (os.FileMode, error) {
+	info, err := os.Stat(location)
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode().Perm(), nil
+}
+
+func (LocalFS) NoOp() error {
+	return nil
+}
+
+func (LocalFS) Close() error {
 	return nil
 }
 
@@ -33,19 +122,32 @@ func createDirIfNotExist(path string) error {
 	return nil
 }
 
-func OsToEntry(d os.DirEntry) types.Entry {
+func OsToEntry(d os.DirEntry) (types.Entry, error) {
+	info, err := d.Info()
+	if err != nil {
+		return types.Entry{}, err
+	}
+	return FileInfoToEntry(info), nil
+}
+
+func FileInfoToEntry(info os.FileInfo) types.Entry {
 	var tp int
 	switch {
-	case d.IsDir():
+	case info.IsDir():
 		tp = types.TypeDirectory
+	case info.Mode()&os.ModeSymlink != 0:
+		tp = types.TypeLink
 	default:
 		tp = types.TypeFile
 	}
-
-	info, _ := d.Info()
+	owner, group := fileOwner(info)
 	return types.Entry{
-		Name: d.Name(),
-		Type: tp,
-		Size: uint64(info.Size()),
+		Name:    info.Name(),
+		Type:    tp,
+		Size:    uint64(info.Size()),
+		ModTime: info.ModTime(),
+		Mode:    info.Mode().Perm(),
+		Owner:   owner,
+		Group:   group,
 	}
 }
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/sftp"
+)
+
+// fileOwner returns owner and group names of local files, sftp reports only ids
+func fileOwner(info os.FileInfo) (owner string, group string) {
+	if stat, ok := info.Sys().(*sftp.FileStat); ok {
+		return strconv.FormatUint(uint64(stat.UID), 10), strconv.FormatUint(uint64(stat.GID), 10)
+	}
+	uid, gid, ok := localOwnerIDs(info)
+	if !ok {
+		return "", ""
+	}
+	return ownerNames.user(uid), ownerNames.group(gid)
+}
+
+// ownerNames caches lookups of local users and groups, listing calls it for each file
+var ownerNames = &idNames{users: map[string]string{}, groups: map[string]string{}}
+
+type idNames struct {
+	mu     sync.Mutex
+	users  map[string]string
+	groups map[string]string
+}
+
+func (n *idNames) user(id string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	name, ok := n.users[id]
+	if !ok {
+		name = id
+		if u, err := user.LookupId(id); err == nil {
+			name = u.Username
+		}
+		n.users[id] = name
+	}
+	return name
+}
+
+func (n *idNames) group(id string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	name, ok := n.groups[id]
+	if !ok {
+		name = id
+		if g, err := user.LookupGroupId(id); err == nil {
+			name = g.Name
+		}
+		n.groups[id] = name
+	}
+	return name
+}
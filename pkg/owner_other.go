@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package pkg
+
+import "os"
+
+// localOwnerIDs is not supported, files have no unix owner
+func localOwnerIDs(os.FileInfo) (uid string, gid string, ok bool) {
+	return "", "", false
+}
@@ -0,0 +1,17 @@
+//go:build !windows && !plan9
+
+package pkg
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+func localOwnerIDs(info os.FileInfo) (uid string, gid string, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", false
+	}
+	return strconv.FormatUint(uint64(stat.Uid), 10), strconv.FormatUint(uint64(stat.Gid), 10), true
+}
@@ -0,0 +1,254 @@
+package pkg
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prathoss/goftp/types"
+)
+
+const (
+	MinConnections     = 1
+	MaxConnections     = 8
+	DefaultConnections = 2
+	// poolIdleCheck is how long a connection may stay unused before it is checked with NoOp
+	poolIdleCheck = 15 * time.Second
+)
+
+// Pool is RemoteFS spreading operations over up to size connections,
+// each operation borrows a connection, opened files hold it until closed
+type Pool struct {
+	dial  func() (RemoteFS, error)
+	slots chan struct{}
+
+	mu     sync.Mutex
+	idle   []pooledConn
+	closed bool
+}
+
+type pooledConn struct {
+	fsys     RemoteFS
+	lastUsed time.Time
+}
+
+func NewPool(dial func() (RemoteFS, error), size int) *Pool {
+	size = Max(MinConnections, Min(size, MaxConnections))
+	return &Pool{
+		dial:  dial,
+		slots: make(chan struct{}, size),
+	}
+}
+
+// Size is the number of operations the pool runs at once
+func (p *Pool) Size() int {
+	return cap(p.slots)
+}
+
+// get waits for free slot and returns idle connection or dials new one
+func (p *Pool) get() (RemoteFS, error) {
+	p.slots <- struct{}{}
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			<-p.slots
+			return nil, net.ErrClosed
+		}
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+			break
+		}
+		conn := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+		// server may have dropped connection which was not used for a while
+		if time.Since(conn.lastUsed) < poolIdleCheck || conn.fsys.NoOp() == nil {
+			return conn.fsys, nil
+		}
+		_ = conn.fsys.Close()
+	}
+	fsys, err := p.dial()
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return fsys, nil
+}
+
+// put returns the connection used by operation which ended with err, broken connections are closed
+func (p *Pool) put(fsys RemoteFS, err error) {
+	defer func() { <-p.slots }()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || isConnectionError(err) {
+		_ = fsys.Close()
+		return
+	}
+	p.idle = append(p.idle, pooledConn{fsys: fsys, lastUsed: time.Now()})
+}
+
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, errReadAborted)
+}
+
+func (p *Pool) with(fn func(fsys RemoteFS) error) error {
+	fsys, err := p.get()
+	if err != nil {
+		return err
+	}
+	err = fn(fsys)
+	p.put(fsys, err)
+	return err
+}
+
+func (p *Pool) List(location string) ([]types.Entry, error) {
+	var entries []types.Entry
+	err := p.with(func(fsys RemoteFS) (err error) {
+		entries, err = fsys.List(location)
+		return err
+	})
+	return entries, err
+}
+
+func (p *Pool) Stat(location string) (types.Entry, error) {
+	var entry types.Entry
+	err := p.with(func(fsys RemoteFS) (err error) {
+		entry, err = fsys.Stat(location)
+		return err
+	})
+	return entry, err
+}
+
+func (p *Pool) Open(location string) (io.ReadCloser, error) {
+	return p.OpenFrom(location, 0)
+}
+
+func (p *Pool) OpenFrom(location string, offset uint64) (io.ReadCloser, error) {
+	fsys, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	r, err := fsys.OpenFrom(location, offset)
+	if err != nil {
+		p.put(fsys, err)
+		return nil, err
+	}
+	return &pooledReader{ReadCloser: r, release: func(err error) { p.put(fsys, err) }}, nil
+}
+
+func (p *Pool) Create(location string) (io.WriteCloser, error) {
+	return p.WriteFrom(location, 0)
+}
+
+func (p *Pool) WriteFrom(location string, offset uint64) (io.WriteCloser, error) {
+	fsys, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	w, err := fsys.WriteFrom(location, offset)
+	if err != nil {
+		p.put(fsys, err)
+		return nil, err
+	}
+	return &pooledWriter{WriteCloser: w, release: func(err error) { p.put(fsys, err) }}, nil
+}
+
+func (p *Pool) Mkdir(location string) error {
+	return p.with(func(fsys RemoteFS) error {
+		return fsys.Mkdir(location)
+	})
+}
+
+func (p *Pool) Remove(location string) error {
+	return p.with(func(fsys RemoteFS) error {
+		return fsys.Remove(location)
+	})
+}
+
+func (p *Pool) Rename(from, to string) error {
+	return p.with(func(fsys RemoteFS) error {
+		return fsys.Rename(from, to)
+	})
+}
+
+func (p *Pool) Chmod(location string, mode os.FileMode) error {
+	return p.with(func(fsys RemoteFS) error {
+		return fsys.Chmod(location, mode)
+	})
+}
+
+// NoOp does nothing, idle connections are checked when they are borrowed
+func (p *Pool) NoOp() error {
+	return nil
+}
+
+// Close closes idle connections, borrowed ones are closed when returned
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	var err error
+	for _, conn := range p.idle {
+		if closeErr := conn.fsys.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	p.idle = nil
+	return err
+}
+
+// pooledReader returns the connection to the pool once closed, read error or error of aborted read decides
+// whether it is reused
+type pooledReader struct {
+	io.ReadCloser
+	release func(error)
+	readErr error
+	once    sync.Once
+}
+
+func (r *pooledReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.readErr = err
+	}
+	return n, err
+}
+
+func (r *pooledReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(func() {
+		if r.readErr != nil {
+			r.release(r.readErr)
+			return
+		}
+		r.release(err)
+	})
+	return err
+}
+
+type pooledWriter struct {
+	io.WriteCloser
+	release func(error)
+	once    sync.Once
+}
+
+func (w *pooledWriter) Close() error {
+	err := w.WriteCloser.Close()
+	w.once.Do(func() { w.release(err) })
+	return err
+}
+
+func (w *pooledWriter) CloseWithError(err error) error {
+	closeErr := CloseWriter(w.WriteCloser, err)
+	w.once.Do(func() { w.release(closeErr) })
+	return closeErr
+}
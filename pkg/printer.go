@@ -3,6 +3,8 @@ package pkg
 import (
 	"fmt"
 	"math"
+
+	"github.com/mattn/go-runewidth"
 )
 
 const (
@@ -65,3 +67,28 @@ func PrettyPrintSize(size uint64) string {
 	unit := units[canUse]
 	return f(float64(size)/unit.unit, unit.text)
 }
+
+// Ellipsize shortens text to the width of terminal cells, the end is replaced by "…"
+func Ellipsize(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return runewidth.Truncate(text, width, "…")
+}
+
+// EllipsizeLeft shortens text to the width of terminal cells keeping the end, useful for paths
+func EllipsizeLeft(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if runewidth.StringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for i := range runes {
+		if tail := string(runes[i:]); runewidth.StringWidth(tail) <= width-1 {
+			return "…" + tail
+		}
+	}
+	return "…"
+}
@@ -0,0 +1,467 @@
+package pkg
+
+import (
+	"context"
+	"path"
+	"sync"
+
+	"github.com/prathoss/goftp/types"
+)
+
+type JobState int
+
+const (
+	JobPending JobState = iota
+	JobActive
+	JobPaused
+	JobFailed
+	JobCancelled
+	JobCompleted
+)
+
+func (s JobState) String() string {
+	switch s {
+	case JobPending:
+		return "pending"
+	case JobActive:
+		return "active"
+	case JobPaused:
+		return "paused"
+	case JobFailed:
+		return "failed"
+	case JobCancelled:
+		return "cancelled"
+	default:
+		return "completed"
+	}
+}
+
+// Finished reports whether the job will not run unless retried
+func (s JobState) Finished() bool {
+	return s == JobFailed || s == JobCancelled || s == JobCompleted
+}
+
+// TransferJob copies single entry, directories with their content, or removes it from the destination
+type TransferJob struct {
+	ID              int
+	Source          RemoteFS
+	SourceRoot      string
+	Entry           types.Entry
+	Destination     RemoteFS
+	DestinationRoot string
+	Options         TransferOptions
+	// Remove makes the job remove the entry from the destination root, there is no source
+	Remove   bool
+	State    JobState
+	Progress Progress
+	Err      error
+	// requested is state set by user while the job is active, applied once the copy stops
+	requested JobState
+	// written are destination files the job started writing, they are resumed when the job is restarted
+	written map[string]struct{}
+	// batch groups jobs added together, conflict answer applied to all is used by whole batch
+	batch int
+}
+
+func (j TransferJob) SourcePath() string {
+	return path.Join(j.SourceRoot, j.Entry.Name)
+}
+
+func (j TransferJob) DestinationPath() string {
+	return path.Join(j.DestinationRoot, j.Entry.Name)
+}
+
+// TransferQueue runs transfer jobs one by one in background
+type TransferQueue struct {
+	mu           sync.Mutex
+	jobs         []*TransferJob
+	nextID       int
+	nextBatch    int
+	cancelActive context.CancelFunc
+	conflict     *pendingConflict
+	nextConflict int
+	closed       bool
+	wake         chan struct{}
+	updates      chan struct{}
+}
+
+func NewTransferQueue() *TransferQueue {
+	q := &TransferQueue{
+		wake:    make(chan struct{}, 1),
+		updates: make(chan struct{}, 1),
+	}
+	go q.run()
+	return q
+}
+
+// Updates signals that jobs changed, signals are merged when not received in time
+func (q *TransferQueue) Updates() <-chan struct{} {
+	return q.updates
+}
+
+func (q *TransferQueue) notify() {
+	select {
+	case q.updates <- struct{}{}:
+	default:
+	}
+}
+
+func (q *TransferQueue) wakeWorker() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *TransferQueue) Add(
+	source RemoteFS,
+	sourceRoot string,
+	entries []types.Entry,
+	destination RemoteFS,
+	destinationRoot string,
+	opts TransferOptions,
+) {
+	q.mu.Lock()
+	q.nextBatch++
+	for _, entry := range entries {
+		q.nextID++
+		q.jobs = append(q.jobs, &TransferJob{
+			ID:              q.nextID,
+			Source:          source,
+			SourceRoot:      sourceRoot,
+			Entry:           entry,
+			Destination:     destination,
+			DestinationRoot: destinationRoot,
+			Options:         opts,
+			State:           JobPending,
+			written:         map[string]struct{}{},
+			batch:           q.nextBatch,
+		})
+	}
+	q.mu.Unlock()
+	q.wakeWorker()
+	q.notify()
+}
+
+// AddRemove adds jobs removing entries located in destinationRoot, directories with their content
+func (q *TransferQueue) AddRemove(destination RemoteFS, destinationRoot string, entries []types.Entry) {
+	q.mu.Lock()
+	q.nextBatch++
+	for _, entry := range entries {
+		q.nextID++
+		q.jobs = append(q.jobs, &TransferJob{
+			ID:              q.nextID,
+			Entry:           entry,
+			Destination:     destination,
+			DestinationRoot: destinationRoot,
+			Remove:          true,
+			State:           JobPending,
+			batch:           q.nextBatch,
+		})
+	}
+	q.mu.Unlock()
+	q.wakeWorker()
+	q.notify()
+}
+
+// Jobs returns snapshot of all jobs in queue order
+func (q *TransferQueue) Jobs() []TransferJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]TransferJob, len(q.jobs))
+	for i, job := range q.jobs {
+		jobs[i] = *job
+	}
+	return jobs
+}
+
+// Unfinished counts jobs which are active, pending or paused
+func (q *TransferQueue) Unfinished() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	count := 0
+	for _, job := range q.jobs {
+		if !job.State.Finished() {
+			count++
+		}
+	}
+	return count
+}
+
+// Drained reports whether no job is active or waiting to run, paused jobs do not run on their own
+func (q *TransferQueue) Drained() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range q.jobs {
+		if job.State == JobPending || job.State == JobActive {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *TransferQueue) Pause(id int) {
+	q.stop(id, JobPaused, JobPending)
+}
+
+func (q *TransferQueue) Cancel(id int) {
+	q.stop(id, JobCancelled, JobPending, JobPaused)
+}
+
+// stop moves the job to state, active job is interrupted first
+func (q *TransferQueue) stop(id int, state JobState, from ...JobState) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := q.find(id)
+	if job == nil {
+		return
+	}
+	if job.State == JobActive {
+		job.requested = state
+		q.cancelActive()
+		return
+	}
+	for _, s := range from {
+		if job.State == s {
+			job.State = state
+			q.notify()
+			return
+		}
+	}
+}
+
+func (q *TransferQueue) Resume(id int) {
+	q.restart(id, JobPaused)
+}
+
+func (q *TransferQueue) Retry(id int) {
+	q.restart(id, JobFailed, JobCancelled)
+}
+
+// restart makes the job pending again, files it has written are continued instead of starting over
+func (q *TransferQueue) restart(id int, from ...JobState) {
+	q.mu.Lock()
+	job := q.find(id)
+	if job == nil {
+		q.mu.Unlock()
+		return
+	}
+	for _, s := range from {
+		if job.State == s {
+			job.State = JobPending
+			job.Err = nil
+			job.Progress = Progress{}
+		}
+	}
+	q.mu.Unlock()
+	q.wakeWorker()
+	q.notify()
+}
+
+// Move shifts the job by offset positions in the queue
+func (q *TransferQueue) Move(id, offset int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, job := range q.jobs {
+		if job.ID != id {
+			continue
+		}
+		target := Max(0, Min(len(q.jobs)-1, i+offset))
+		for ; i < target; i++ {
+			q.jobs[i], q.jobs[i+1] = q.jobs[i+1], q.jobs[i]
+		}
+		for ; i > target; i-- {
+			q.jobs[i], q.jobs[i-1] = q.jobs[i-1], q.jobs[i]
+		}
+		q.notify()
+		return
+	}
+}
+
+// ClearFinished removes completed and cancelled jobs, failed are kept for retry
+func (q *TransferQueue) ClearFinished() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := q.jobs[:0]
+	for _, job := range q.jobs {
+		if job.State != JobCompleted && job.State != JobCancelled {
+			jobs = append(jobs, job)
+		}
+	}
+	q.jobs = jobs
+	q.notify()
+}
+
+// Close stops the queue, active job is interrupted
+func (q *TransferQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	if q.cancelActive != nil {
+		q.cancelActive()
+	}
+	q.mu.Unlock()
+	q.wakeWorker()
+}
+
+func (q *TransferQueue) find(id int) *TransferJob {
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+func (q *TransferQueue) run() {
+	for {
+		job, ctx := q.next()
+		if job == nil {
+			return
+		}
+		if job.Remove {
+			q.finish(job, RemoveAll(job.Destination, job.DestinationRoot, []types.Entry{job.Entry}))
+			continue
+		}
+		err := CopyWithProgress(ctx, job.Source, job.SourceRoot, []types.Entry{job.Entry}, job.Destination, job.DestinationRoot, q.options(job), func(p Progress) {
+			q.mu.Lock()
+			job.Progress = p
+			q.mu.Unlock()
+			q.notify()
+		})
+		q.finish(job, err)
+	}
+}
+
+// options adds conflict resolution by user and tracking of written files to the job options
+func (q *TransferQueue) options(job *TransferJob) TransferOptions {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	opts := job.Options
+	opts.Resolve = func(ctx context.Context, conflict Conflict) (ConflictPolicy, bool, error) {
+		return q.ask(ctx, job.ID, conflict)
+	}
+	opts.written = make(map[string]struct{}, len(job.written))
+	for location := range job.written {
+		opts.written[location] = struct{}{}
+	}
+	opts.onWrite = func(destinationPath string) {
+		q.mu.Lock()
+		job.written[destinationPath] = struct{}{}
+		q.mu.Unlock()
+	}
+	return opts
+}
+
+// ConflictRequest is conflict of a running job waiting for user decision
+type ConflictRequest struct {
+	ID       int
+	JobID    int
+	Conflict Conflict
+}
+
+type pendingConflict struct {
+	request ConflictRequest
+	answer  chan conflictAnswer
+}
+
+type conflictAnswer struct {
+	policy     ConflictPolicy
+	applyToAll bool
+}
+
+// ask waits until the conflict is resolved by ResolveConflict or the job is stopped
+func (q *TransferQueue) ask(ctx context.Context, jobID int, conflict Conflict) (ConflictPolicy, bool, error) {
+	q.mu.Lock()
+	q.nextConflict++
+	pending := &pendingConflict{
+		request: ConflictRequest{ID: q.nextConflict, JobID: jobID, Conflict: conflict},
+		answer:  make(chan conflictAnswer, 1),
+	}
+	q.conflict = pending
+	q.mu.Unlock()
+	q.notify()
+	defer func() {
+		q.mu.Lock()
+		if q.conflict == pending {
+			q.conflict = nil
+		}
+		q.mu.Unlock()
+		q.notify()
+	}()
+	select {
+	case answer := <-pending.answer:
+		return answer.policy, answer.applyToAll, nil
+	case <-ctx.Done():
+		return ConflictAsk, false, ctx.Err()
+	}
+}
+
+// PendingConflict returns conflict waiting for decision
+func (q *TransferQueue) PendingConflict() (ConflictRequest, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.conflict == nil {
+		return ConflictRequest{}, false
+	}
+	return q.conflict.request, true
+}
+
+// ResolveConflict answers the conflict request with id, ConflictAsk is not a valid answer
+func (q *TransferQueue) ResolveConflict(id int, policy ConflictPolicy, applyToAll bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.conflict == nil || q.conflict.request.ID != id || policy == ConflictAsk {
+		return
+	}
+	q.conflict.answer <- conflictAnswer{policy: policy, applyToAll: applyToAll}
+	if job := q.find(q.conflict.request.JobID); job != nil && applyToAll {
+		for _, other := range q.jobs {
+			if other.batch == job.batch {
+				other.Options.Conflict = policy
+			}
+		}
+	}
+	q.conflict = nil
+}
+
+// next blocks until there is pending job and marks it active, nil is returned when queue is closed
+func (q *TransferQueue) next() (*TransferJob, context.Context) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return nil, nil
+		}
+		for _, job := range q.jobs {
+			if job.State != JobPending {
+				continue
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			job.State = JobActive
+			job.requested = JobActive
+			q.cancelActive = cancel
+			q.mu.Unlock()
+			q.notify()
+			return job, ctx
+		}
+		q.mu.Unlock()
+		<-q.wake
+	}
+}
+
+func (q *TransferQueue) finish(job *TransferJob, err error) {
+	q.mu.Lock()
+	q.cancelActive()
+	q.cancelActive = nil
+	switch {
+	case err == nil:
+		job.State = JobCompleted
+	case job.requested != JobActive:
+		job.State = job.requested
+	default:
+		job.State = JobFailed
+		job.Err = err
+	}
+	q.mu.Unlock()
+	q.notify()
+}
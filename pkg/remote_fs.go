@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/prathoss/goftp/types"
+)
+
+// RemoteFS is a filesystem which can be browsed and transferred between, local disk being one of them.
+// Locations are always absolute and slash separated.
+type RemoteFS interface {
+	List(location string) ([]types.Entry, error)
+	Stat(location string) (types.Entry, error)
+	Open(location string) (io.ReadCloser, error)
+	// OpenFrom opens the file for reading starting at offset
+	OpenFrom(location string, offset uint64) (io.ReadCloser, error)
+	// Create truncates the file if it already exists
+	Create(location string) (io.WriteCloser, error)
+	// WriteFrom opens the file for writing starting at offset, content before the offset is kept.
+	// Writers which would finish failed write as complete file on Close implement AbortWriter.
+	WriteFrom(location string, offset uint64) (io.WriteCloser, error)
+	Mkdir(location string) error
+	// Remove removes a file or an empty directory
+	Remove(location string) error
+	Rename(from, to string) error
+	Chmod(location string, mode os.FileMode) error
+	// NoOp keeps the connection from timing out
+	NoOp() error
+	Close() error
+}
+
+var ErrNotSupported = errors.New("not supported")
+
+// AbortWriter aborts the write, the file is not finished as complete
+type AbortWriter interface {
+	CloseWithError(err error) error
+}
+
+// CloseWriter closes w after writing ended with err, failed write is aborted when w supports it
+func CloseWriter(w io.WriteCloser, err error) error {
+	if aborter, ok := w.(AbortWriter); ok && err != nil {
+		return aborter.CloseWithError(err)
+	}
+	return w.Close()
+}
+
+// Dial connects to the server with the protocol from the configuration
+func Dial(conf ServerConf, password string) (RemoteFS, error) {
+	switch conf.Protocol {
+	case ProtocolSftp:
+		return DialSftp(conf, password)
+	default:
+		return DialFtp(conf, password)
+	}
+}
+
+func mkdirIfNotExist(fsys RemoteFS, location string) error {
+	entry, err := fsys.Stat(location)
+	if err == nil && entry.Type == types.TypeDirectory {
+		return nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return fsys.Mkdir(location)
+}
+
+// MkdirAll creates directory with missing parents
+func MkdirAll(fsys RemoteFS, location string) error {
+	if parent := path.Dir(location); parent != location {
+		if err := MkdirAll(fsys, parent); err != nil {
+			return err
+		}
+	}
+	return mkdirIfNotExist(fsys, location)
+}
+
+// CreateEmpty creates empty file, existing entry is not overwritten
+func CreateEmpty(fsys RemoteFS, location string) error {
+	if err := notExists(fsys, "create", location); err != nil {
+		return err
+	}
+	w, err := fsys.Create(location)
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// RemoveAll removes entries located in location, directories are removed with their content
+func RemoveAll(fsys RemoteFS, location string, entries []types.Entry) error {
+	for _, entry := range entries {
+		absolutePath := path.Join(location, entry.Name)
+		if entry.Type == types.TypeDirectory {
+			children, err := fsys.List(absolutePath)
+			if err != nil {
+				return err
+			}
+			if err := RemoveAll(fsys, absolutePath, children); err != nil {
+				return err
+			}
+		}
+		if err := fsys.Remove(absolutePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func notExistError(op, location string) error {
+	return &fs.PathError{Op: op, Path: location, Err: fs.ErrNotExist}
+}
@@ -0,0 +1,156 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rename changes name of an entry in a location
+type Rename struct {
+	From string
+	To   string
+}
+
+var (
+	renameCounterPattern = regexp.MustCompile(`\{n(?::(\d+))?\}`)
+	renameRegexpPattern  = regexp.MustCompile(`^s/((?:[^/\\]|\\.)*)/((?:[^/\\]|\\.)*)/$`)
+)
+
+// PlanRenames computes new names, expression is either template or regular expression replacement.
+// Template replaces {name} with the whole name, {base} with name without extension, {ext} with extension
+// including the dot and {n} with counter starting at 1, {n:3} pads the counter with zeros to 3 digits.
+// Replacement in form s/regexp/replacement/ uses $1 for groups.
+// Names which do not change are left out.
+func PlanRenames(names []string, expression string) ([]Rename, error) {
+	rename, err := parseRenameExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	renames := make([]Rename, 0, len(names))
+	targets := make(map[string]string, len(names))
+	for i, name := range names {
+		to := rename(name, i+1)
+		if err := ValidateName(to); err != nil {
+			return nil, fmt.Errorf("renaming %q: %w", name, err)
+		}
+		if other, ok := targets[to]; ok {
+			return nil, fmt.Errorf("both %q and %q would be renamed to %q", other, name, to)
+		}
+		targets[to] = name
+		if to != name {
+			renames = append(renames, Rename{From: name, To: to})
+		}
+	}
+	return renames, nil
+}
+
+func parseRenameExpression(expression string) (func(name string, counter int) string, error) {
+	if match := renameRegexpPattern.FindStringSubmatch(expression); match != nil {
+		re, err := regexp.Compile(strings.ReplaceAll(match[1], `\/`, "/"))
+		if err != nil {
+			return nil, err
+		}
+		replacement := strings.ReplaceAll(match[2], `\/`, "/")
+		return func(name string, _ int) string {
+			return re.ReplaceAllString(name, replacement)
+		}, nil
+	}
+	if expression == "" {
+		return nil, errors.New("empty rename template")
+	}
+	return func(name string, counter int) string {
+		ext := path.Ext(name)
+		if ext == name {
+			// hidden files like .profile have no extension
+			ext = ""
+		}
+		result := renameCounterPattern.ReplaceAllStringFunc(expression, func(placeholder string) string {
+			width, _ := strconv.Atoi(renameCounterPattern.FindStringSubmatch(placeholder)[1])
+			return fmt.Sprintf("%0*d", width, counter)
+		})
+		return strings.NewReplacer(
+			"{name}", name,
+			"{base}", strings.TrimSuffix(name, ext),
+			"{ext}", ext,
+		).Replace(result)
+	}, nil
+}
+
+// ValidateName checks name can be used for an entry in a directory
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("empty name")
+	case name == "." || name == "..":
+		return fmt.Errorf("name %q is reserved", name)
+	case strings.Contains(name, "/"):
+		return fmt.Errorf("name %q contains /", name)
+	}
+	return nil
+}
+
+// RenameAll renames entries in location, existing entries are not overwritten.
+// Entries are renamed through temporary names when new name of one is old name of another.
+func RenameAll(fsys RemoteFS, location string, renames []Rename) error {
+	sources := make(map[string]struct{}, len(renames))
+	for _, rename := range renames {
+		sources[rename.From] = struct{}{}
+	}
+	chained := false
+	for _, rename := range renames {
+		if _, ok := sources[rename.To]; ok {
+			chained = true
+			continue
+		}
+		if err := notExists(fsys, "rename", path.Join(location, rename.To)); err != nil {
+			return err
+		}
+	}
+	if !chained {
+		for _, rename := range renames {
+			if err := fsys.Rename(path.Join(location, rename.From), path.Join(location, rename.To)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	temporary := make([]Rename, len(renames))
+	for i, rename := range renames {
+		temporary[i] = Rename{From: rename.From, To: fmt.Sprintf(".goftp-rename-%d-%s", i, rename.From)}
+		if err := fsys.Rename(path.Join(location, temporary[i].From), path.Join(location, temporary[i].To)); err != nil {
+			return err
+		}
+	}
+	for i, rename := range renames {
+		if err := fsys.Rename(path.Join(location, temporary[i].To), path.Join(location, rename.To)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Move moves entry to another location, which may be in another directory, existing entry is not overwritten
+func Move(fsys RemoteFS, from, to string) error {
+	if err := notExists(fsys, "rename", to); err != nil {
+		return err
+	}
+	return fsys.Rename(from, to)
+}
+
+// notExists fails with fs.ErrExist when location exists
+func notExists(fsys RemoteFS, op, location string) error {
+	_, err := fsys.Stat(location)
+	switch {
+	case err == nil:
+		return &fs.PathError{Op: op, Path: location, Err: fs.ErrExist}
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	default:
+		return err
+	}
+}
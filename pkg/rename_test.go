@@ -0,0 +1,200 @@
+package pkg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPlanRenames(t *testing.T) {
+	tests := []struct {
+		name       string
+		names      []string
+		expression string
+		want       []Rename
+		wantErr    bool
+	}{
+		{
+			name:       "counter",
+			names:      []string{"a.jpg", "b.jpg"},
+			expression: "photo-{n}{ext}",
+			want:       []Rename{{From: "a.jpg", To: "photo-1.jpg"}, {From: "b.jpg", To: "photo-2.jpg"}},
+		},
+		{
+			name:       "padded counter",
+			names:      []string{"a.jpg", "b.jpg"},
+			expression: "{n:3}-{base}{ext}",
+			want:       []Rename{{From: "a.jpg", To: "001-a.jpg"}, {From: "b.jpg", To: "002-b.jpg"}},
+		},
+		{
+			name:       "counter wider than padding",
+			names:      []string{"a", "b", "c"},
+			expression: "{n:0}{name}",
+			want:       []Rename{{From: "a", To: "1a"}, {From: "b", To: "2b"}, {From: "c", To: "3c"}},
+		},
+		{
+			name:       "extension of last dot",
+			names:      []string{"backup.tar.gz"},
+			expression: "{base}.old{ext}",
+			want:       []Rename{{From: "backup.tar.gz", To: "backup.tar.old.gz"}},
+		},
+		{
+			name:       "hidden file has no extension",
+			names:      []string{".profile"},
+			expression: "{base}{ext}.bak",
+			want:       []Rename{{From: ".profile", To: ".profile.bak"}},
+		},
+		{
+			name:       "unchanged names are left out",
+			names:      []string{"a.txt", "b.md"},
+			expression: "s/\\.txt$/.log/",
+			want:       []Rename{{From: "a.txt", To: "a.log"}},
+		},
+		{
+			name:       "regexp groups",
+			names:      []string{"IMG_2020_01.jpg"},
+			expression: "s/^IMG_(\\d+)_(\\d+)/$2-$1/",
+			want:       []Rename{{From: "IMG_2020_01.jpg", To: "01-2020.jpg"}},
+		},
+		{
+			name:       "escaped slash in regexp",
+			names:      []string{"a\\b"},
+			expression: "s/\\\\/-/",
+			want:       []Rename{{From: "a\\b", To: "a-b"}},
+		},
+		{
+			name:       "template without placeholders names all the same",
+			names:      []string{"a", "b"},
+			expression: "same",
+			wantErr:    true,
+		},
+		{
+			name:       "slash in new name",
+			names:      []string{"a"},
+			expression: "dir/{name}",
+			wantErr:    true,
+		},
+		{
+			name:       "escaped slash in replacement makes invalid name",
+			names:      []string{"a-b"},
+			expression: "s/-/\\//",
+			wantErr:    true,
+		},
+		{
+			name:       "empty name",
+			names:      []string{"a"},
+			expression: "s/a//",
+			wantErr:    true,
+		},
+		{
+			name:       "empty template",
+			names:      []string{"a"},
+			expression: "",
+			wantErr:    true,
+		},
+		{
+			name:       "invalid regexp",
+			names:      []string{"a"},
+			expression: "s/(/x/",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PlanRenames(tt.names, tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlanRenames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanRenames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		renames []Rename
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "plain",
+			files:   []string{"a", "b"},
+			renames: []Rename{{From: "a", To: "c"}, {From: "b", To: "d"}},
+			want:    []string{"c:a", "d:b"},
+		},
+		{
+			name:    "swap",
+			files:   []string{"a", "b"},
+			renames: []Rename{{From: "a", To: "b"}, {From: "b", To: "a"}},
+			want:    []string{"a:b", "b:a"},
+		},
+		{
+			name:    "chain",
+			files:   []string{"1", "2", "3"},
+			renames: []Rename{{From: "1", To: "2"}, {From: "2", To: "3"}, {From: "3", To: "4"}},
+			want:    []string{"2:1", "3:2", "4:3"},
+		},
+		{
+			name:    "existing entry is not overwritten",
+			files:   []string{"a", "b"},
+			renames: []Rename{{From: "a", To: "b"}},
+			want:    []string{"a", "b"},
+			wantErr: fs.ErrExist,
+		},
+		{
+			name:    "chain into existing entry",
+			files:   []string{"a", "b", "c"},
+			renames: []Rename{{From: "a", To: "b"}, {From: "b", To: "c"}},
+			want:    []string{"a", "b", "c"},
+			wantErr: fs.ErrExist,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := RenameAll(LocalFS{}, filepath.ToSlash(dir), tt.renames)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RenameAll() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := renameTestFiles(t, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// renameTestFiles lists the directory, file with content other than its name is listed as name:content
+func renameTestFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := entry.Name()
+		if string(content) != file {
+			file += ":" + string(content)
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PasswordStore is where password of saved connection is remembered
+type PasswordStore string
+
+const (
+	PasswordStoreNone          PasswordStore = ""
+	PasswordStoreVault         PasswordStore = "vault"
+	PasswordStoreSecretService PasswordStore = "secretService"
+)
+
+// PasswordStores lists stores in the order they are offered on the login screen
+var PasswordStores = []PasswordStore{PasswordStoreNone, PasswordStoreVault, PasswordStoreSecretService}
+
+func (s PasswordStore) String() string {
+	switch s {
+	case PasswordStoreNone:
+		return "no"
+	case PasswordStoreSecretService:
+		return "secret service"
+	default:
+		return string(s)
+	}
+}
+
+func ParsePasswordStore(s string) (PasswordStore, error) {
+	for _, store := range PasswordStores {
+		if store.String() == s || string(store) == s {
+			return store, nil
+		}
+	}
+	return PasswordStoreNone, fmt.Errorf("unknown password store %q", s)
+}
+
+var ErrNoPassword = errors.New("password is not stored")
+
+// Secrets keeps passwords of saved connections
+type Secrets interface {
+	// Password returns ErrNoPassword when there is none for the connection
+	Password(conf ServerConf) (string, error)
+	SetPassword(conf ServerConf, password string) error
+	DeletePassword(conf ServerConf) error
+}
+
+// secretServiceApplication is attribute identifying passwords of goftp in the secret service
+const secretServiceApplication = "goftp"
+
+// SecretService stores passwords in freedesktop secret service through secret-tool of libsecret
+type SecretService struct{}
+
+// SecretServiceAvailable reports whether secret-tool is installed and there is session bus to reach the service
+func SecretServiceAvailable() bool {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return false
+	}
+	return os.Getenv("DBUS_SESSION_BUS_ADDRESS") != ""
+}
+
+func (SecretService) Password(conf ServerConf) (string, error) {
+	out, err := secretTool(nil, "lookup", "application", secretServiceApplication, "connection", conf.ID())
+	if err != nil {
+		return "", err
+	}
+	// lookup of missing password succeeds with empty output on some versions
+	if len(out) == 0 {
+		return "", ErrNoPassword
+	}
+	return string(out), nil
+}
+
+func (SecretService) SetPassword(conf ServerConf, password string) error {
+	_, err := secretTool(
+		strings.NewReader(password),
+		"store",
+		"--label", fmt.Sprintf("goftp %s", conf.ID()),
+		"application", secretServiceApplication,
+		"connection", conf.ID(),
+	)
+	return err
+}
+
+func (SecretService) DeletePassword(conf ServerConf) error {
+	_, err := secretTool(nil, "clear", "application", secretServiceApplication, "connection", conf.ID())
+	return err
+}
+
+func secretTool(stdin *strings.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("secret-tool", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && args[0] == "lookup" && stderr.Len() == 0:
+		return nil, ErrNoPassword
+	case err != nil && stderr.Len() > 0:
+		return nil, fmt.Errorf("secret-tool %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	case err != nil:
+		return nil, fmt.Errorf("secret-tool %s: %w", args[0], err)
+	}
+	return out, nil
+}
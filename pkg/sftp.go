@@ -0,0 +1,279 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+
+	"github.com/pkg/sftp"
+	"github.com/prathoss/goftp/types"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SftpFS is RemoteFS over sftp session together with the ssh connection it runs over
+type SftpFS struct {
+	client *sftp.Client
+	ssh    *ssh.Client
+}
+
+func (c *SftpFS) NoOp() error {
+	_, _, err := c.ssh.SendRequest("keepalive@openssh.com", true, nil)
+	return err
+}
+
+func (c *SftpFS) Close() error {
+	_ = c.client.Close()
+	return c.ssh.Close()
+}
+
+// UnknownHostKeyError is returned when the host is not in known_hosts, the user has to check the fingerprint
+// of the key before it is trusted by TrustHostKey
+type UnknownHostKeyError struct {
+	Host string
+	Key  ssh.PublicKey
+}
+
+func (e *UnknownHostKeyError) Error() string {
+	return fmt.Sprintf("host %s is not known, %s key fingerprint is %s", e.Host, e.Key.Type(), e.Fingerprint())
+}
+
+func (e *UnknownHostKeyError) Fingerprint() string {
+	return ssh.FingerprintSHA256(e.Key)
+}
+
+// TrustHostKey remembers the key of the host in ~/.ssh/known_hosts
+func TrustHostKey(e *UnknownHostKeyError) error {
+	knownHostsPath, err := knownHostsFile()
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(knownHostsPath, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(e.Host)}, e.Key))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// DialSftp connects to the server authenticating with ssh agent, key file and password in this order,
+// password is used as key passphrase when the key file is encrypted
+func DialSftp(conf ServerConf, password string) (*SftpFS, error) {
+	auths, agentConn, err := sshAuthMethods(conf, password)
+	if err != nil {
+		return nil, err
+	}
+	if agentConn != nil {
+		// the agent signs only during the handshake
+		defer agentConn.Close()
+	}
+	check, err := knownHostsCallback()
+	if err != nil {
+		return nil, err
+	}
+	// the handshake error does not wrap the one of the callback, so the unknown key is kept here
+	var unknownKey *UnknownHostKeyError
+	sshClient, err := ssh.Dial("tcp", conf.DialAddress(), &ssh.ClientConfig{
+		User: conf.User,
+		Auth: auths,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			err := check(hostname, remote, key)
+			errors.As(err, &unknownKey)
+			return err
+		},
+		Timeout: conf.DialTimeout(),
+	})
+	if unknownKey != nil {
+		return nil, unknownKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, err
+	}
+	return &SftpFS{client: client, ssh: sshClient}, nil
+}
+
+func (c *SftpFS) List(location string) ([]types.Entry, error) {
+	infos, err := c.client.ReadDir(location)
+	if err != nil {
+		return nil, err
+	}
+	entries := MapSlice(infos, FileInfoToEntry)
+	for i, entry := range entries {
+		if entry.Type == types.TypeLink {
+			entries[i].LinkTarget, _ = c.client.ReadLink(path.Join(location, entry.Name))
+		}
+	}
+	return entries, nil
+}
+
+// Stat does not follow links, like the listing they are entries of their own
+func (c *SftpFS) Stat(location string) (types.Entry, error) {
+	info, err := c.client.Lstat(location)
+	if err != nil {
+		return types.Entry{}, err
+	}
+	entry := FileInfoToEntry(info)
+	if entry.Type == types.TypeLink {
+		entry.LinkTarget, _ = c.client.ReadLink(location)
+	}
+	return entry, nil
+}
+
+func (c *SftpFS) Open(location string) (io.ReadCloser, error) {
+	return c.client.Open(location)
+}
+
+func (c *SftpFS) OpenFrom(location string, offset uint64) (io.ReadCloser, error) {
+	f, err := c.client.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (c *SftpFS) Create(location string) (io.WriteCloser, error) {
+	return c.client.Create(location)
+}
+
+func (c *SftpFS) WriteFrom(location string, offset uint64) (io.WriteCloser, error) {
+	f, err := c.client.OpenFile(location, os.O_CREATE|os.O_WRONLY)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(int64(offset)); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (c *SftpFS) Mkdir(location string) error {
+	return c.client.Mkdir(location)
+}
+
+func (c *SftpFS) Remove(location string) error {
+	return c.client.Remove(location)
+}
+
+func (c *SftpFS) Rename(from, to string) error {
+	return c.client.Rename(from, to)
+}
+
+func (c *SftpFS) Chmod(location string, mode os.FileMode) error {
+	return c.client.Chmod(location, mode)
+}
+
+// Mode is used for entries listed without permission bits, which is also the case of mode 000
+func (c *SftpFS) Mode(location string) (os.FileMode, error) {
+	info, err := c.client.Stat(location)
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode().Perm(), nil
+}
+
+// sshAuthMethods returns connection to ssh agent when it is used, the caller closes it
+func sshAuthMethods(conf ServerConf, password string) ([]ssh.AuthMethod, net.Conn, error) {
+	var keyAuth ssh.AuthMethod
+	if conf.KeyFile != "" {
+		key, err := os.ReadFile(conf.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		var passErr *ssh.PassphraseMissingError
+		if errors.As(err, &passErr) {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(password))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		keyAuth = ssh.PublicKeys(signer)
+	}
+	var auths []ssh.AuthMethod
+	var agentConn net.Conn
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			agentConn = conn
+			auths = append(auths, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	if keyAuth != nil {
+		auths = append(auths, keyAuth)
+	}
+	if password != "" {
+		auths = append(auths,
+			ssh.Password(password),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, echos []bool) ([]string, error) {
+				// servers may send rounds with instructions only
+				if len(questions) == 0 {
+					return nil, nil
+				}
+				// other prompts ask for one time codes or other factors, the password must not be sent to them
+				if len(questions) != 1 || echos[0] {
+					return nil, fmt.Errorf("server asks %q, only password prompt can be answered", questions)
+				}
+				return []string{password}, nil
+			}),
+		)
+	}
+	return auths, agentConn, nil
+}
+
+// knownHostsFile returns path of ~/.ssh/known_hosts, it is created when missing
+func knownHostsFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	knownHostsPath := path.Join(home, ".ssh", "known_hosts")
+	if err := createDirIfNotExist(path.Dir(knownHostsPath)); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(knownHostsPath, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	return knownHostsPath, f.Close()
+}
+
+// knownHostsCallback verifies host keys against ~/.ssh/known_hosts,
+// keys of hosts seen for the first time are rejected with UnknownHostKeyError
+func knownHostsCallback() (ssh.HostKeyCallback, error) {
+	knownHostsPath, err := knownHostsFile()
+	if err != nil {
+		return nil, err
+	}
+	check, err := knownhosts.New(knownHostsPath)
+	if err != nil {
+		return nil, err
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := check(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			return &UnknownHostKeyError{Host: hostname, Key: key}
+		}
+		return err
+	}, nil
+}
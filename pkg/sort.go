@@ -0,0 +1,164 @@
+package pkg
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/prathoss/goftp/types"
+)
+
+// SortKey is what file lists are sorted by
+type SortKey string
+
+const (
+	SortByName      SortKey = ""
+	SortBySize      SortKey = "size"
+	SortByModTime   SortKey = "modified"
+	SortByExtension SortKey = "extension"
+)
+
+// SortKeys lists keys in the order they are offered to user
+var SortKeys = []SortKey{SortByName, SortBySize, SortByModTime, SortByExtension}
+
+func (k SortKey) String() string {
+	if k == SortByName {
+		return "name"
+	}
+	return string(k)
+}
+
+// SortOrder of a file list, zero value sorts by name with directories first
+type SortOrder struct {
+	By         SortKey `yaml:"by,omitempty"`
+	Descending bool    `yaml:"descending,omitempty"`
+	// Mixed does not put directories before files
+	Mixed bool `yaml:"mixed,omitempty"`
+	// Natural compares numbers in names by their value and ignores case
+	Natural bool `yaml:"natural,omitempty"`
+}
+
+func (o SortOrder) String() string {
+	direction := "↑"
+	if o.Descending {
+		direction = "↓"
+	}
+	s := fmt.Sprintf("%s %s", o.By, direction)
+	if !o.Mixed {
+		s += ", dirs first"
+	}
+	if o.Natural {
+		s += ", natural"
+	}
+	return s
+}
+
+// SortEntries sorts in place, entries equal by the key are ordered by name
+func SortEntries(entries []types.Entry, order SortOrder) {
+	compareNames := strings.Compare
+	if order.Natural {
+		compareNames = NaturalCompare
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		ei, ej := entries[i], entries[j]
+		if !order.Mixed && ei.Type != ej.Type {
+			return ei.Type < ej.Type
+		}
+		c := 0
+		switch order.By {
+		case SortBySize:
+			c = compareUint(ei.Size, ej.Size)
+		case SortByModTime:
+			c = compareTimes(ei.ModTime, ej.ModTime)
+		case SortByExtension:
+			c = strings.Compare(strings.ToLower(path.Ext(ei.Name)), strings.ToLower(path.Ext(ej.Name)))
+		}
+		if c == 0 {
+			c = compareNames(ei.Name, ej.Name)
+		}
+		if order.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NaturalCompare orders "file2" before "file10", letters are compared ignoring case,
+// strings equal this way are compared bytewise
+func NaturalCompare(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if isDigit(ra[i]) && isDigit(rb[j]) {
+			ni, nj := digitsEnd(ra, i), digitsEnd(rb, j)
+			if c := compareNumbers(string(ra[i:ni]), string(rb[j:nj])); c != 0 {
+				return c
+			}
+			i, j = ni, nj
+			continue
+		}
+		ca, cb := unicode.ToLower(ra[i]), unicode.ToLower(rb[j])
+		if ca != cb {
+			if ca < cb {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	switch {
+	case len(ra)-i < len(rb)-j:
+		return -1
+	case len(ra)-i > len(rb)-j:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func digitsEnd(runes []rune, start int) int {
+	end := start
+	for end < len(runes) && isDigit(runes[end]) {
+		end++
+	}
+	return end
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// compareNumbers compares digit strings of any length by value
+func compareNumbers(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return compareUint(uint64(len(a)), uint64(len(b)))
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prathoss/goftp/types"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "file2", b: "file10", want: -1},
+		{a: "file10", b: "file2", want: 1},
+		{a: "file2", b: "file2", want: 0},
+		{a: "File2", b: "file10", want: -1},
+		{a: "a", b: "B", want: -1},
+		{a: "B", b: "a", want: 1},
+		{a: "File", b: "file", want: -1},
+		{a: "file02", b: "file2", want: -1},
+		{a: "file002", b: "file10", want: -1},
+		{a: "v1.10.2", b: "v1.9.12", want: 1},
+		{a: "x9y", b: "x9z", want: -1},
+		{a: "img", b: "img1", want: -1},
+		{a: "1a", b: "a", want: -1},
+		{a: "file99999999999999999999", b: "file100000000000000000000", want: -1},
+		{a: "čaj2", b: "čaj10", want: -1},
+		{a: "", b: "a", want: -1},
+	}
+	for _, tt := range tests {
+		if got := NaturalCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("NaturalCompare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSortEntries(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	entries := []types.Entry{
+		{Name: "b10.txt", Type: types.TypeFile, Size: 30, ModTime: older},
+		{Name: "dir2", Type: types.TypeDirectory, ModTime: newer},
+		{Name: "a.zip", Type: types.TypeFile, Size: 20, ModTime: newer},
+		{Name: "B2.txt", Type: types.TypeFile, Size: 20, ModTime: older},
+		{Name: "Dir10", Type: types.TypeDirectory, ModTime: older},
+		{Name: "link", Type: types.TypeLink, Size: 5, ModTime: newer},
+	}
+	tests := []struct {
+		name  string
+		order SortOrder
+		want  []string
+	}{
+		{
+			name:  "name with directories first",
+			order: SortOrder{},
+			want:  []string{"Dir10", "dir2", "B2.txt", "a.zip", "b10.txt", "link"},
+		},
+		{
+			name:  "descending keeps directories first",
+			order: SortOrder{Descending: true},
+			want:  []string{"dir2", "Dir10", "b10.txt", "a.zip", "B2.txt", "link"},
+		},
+		{
+			name:  "mixed",
+			order: SortOrder{Mixed: true},
+			want:  []string{"B2.txt", "Dir10", "a.zip", "b10.txt", "dir2", "link"},
+		},
+		{
+			name:  "natural",
+			order: SortOrder{Natural: true},
+			want:  []string{"dir2", "Dir10", "a.zip", "B2.txt", "b10.txt", "link"},
+		},
+		{
+			name:  "natural mixed",
+			order: SortOrder{Natural: true, Mixed: true},
+			want:  []string{"a.zip", "B2.txt", "b10.txt", "dir2", "Dir10", "link"},
+		},
+		{
+			name:  "size with equal sizes by name",
+			order: SortOrder{By: SortBySize, Mixed: true},
+			want:  []string{"Dir10", "dir2", "link", "B2.txt", "a.zip", "b10.txt"},
+		},
+		{
+			name:  "modification time descending",
+			order: SortOrder{By: SortByModTime, Descending: true},
+			want:  []string{"dir2", "Dir10", "a.zip", "b10.txt", "B2.txt", "link"},
+		},
+		{
+			name:  "extension",
+			order: SortOrder{By: SortByExtension, Mixed: true},
+			want:  []string{"Dir10", "dir2", "link", "B2.txt", "b10.txt", "a.zip"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := append([]types.Entry(nil), entries...)
+			SortEntries(sorted, tt.order)
+			got := make([]string, len(sorted))
+			for i, entry := range sorted {
+				got[i] = entry.Name
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package pkg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+type TLSMode string
+
+const (
+	TLSModeNone     TLSMode = ""
+	TLSModeExplicit TLSMode = "explicit"
+	TLSModeImplicit TLSMode = "implicit"
+)
+
+// TLSModes lists modes in the order they are offered on the login screen
+var TLSModes = []TLSMode{TLSModeNone, TLSModeExplicit, TLSModeImplicit}
+
+func (m TLSMode) String() string {
+	if m == TLSModeNone {
+		return "none"
+	}
+	return string(m)
+}
+
+func ParseTLSMode(s string) (TLSMode, error) {
+	for _, mode := range TLSModes {
+		if mode.String() == s || string(mode) == s {
+			return mode, nil
+		}
+	}
+	return TLSModeNone, fmt.Errorf("unknown tls mode %q", s)
+}
+
+// DefaultPort returns the well known ftp port for the mode
+func (m TLSMode) DefaultPort() int {
+	if m == TLSModeImplicit {
+		return 990
+	}
+	return 21
+}
+
+type TLSConf struct {
+	Mode               TLSMode `yaml:"mode,omitempty"`
+	CAFile             string  `yaml:"caFile,omitempty"`
+	CertFile           string  `yaml:"certFile,omitempty"`
+	KeyFile            string  `yaml:"keyFile,omitempty"`
+	InsecureSkipVerify bool    `yaml:"insecureSkipVerify,omitempty"`
+}
+
+var ErrInvalidCA = errors.New("no certificates found in CA bundle")
+
+func (t TLSConf) Config(serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		// servers commonly require the data connection to resume the control connection session
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: %w", t.CAFile, ErrInvalidCA)
+		}
+		cfg.RootCAs = pool
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		keyFile := t.KeyFile
+		if keyFile == "" {
+			// key may be bundled in the same pem file as the certificate
+			keyFile = t.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(t.CertFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
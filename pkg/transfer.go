@@ -0,0 +1,456 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/prathoss/goftp/types"
+)
+
+// Progress of a running copy
+type Progress struct {
+	// File is path of the file being copied
+	File  string
+	Done  uint64
+	Total uint64
+	// Skipped is part of Done which was not transferred, because it was skipped or resumed
+	Skipped uint64
+	Started time.Time
+}
+
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return float64(p.Done) / float64(p.Total)
+}
+
+// Rate returns average speed in bytes per second
+func (p Progress) Rate() float64 {
+	elapsed := time.Since(p.Started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Done-p.Skipped) / elapsed
+}
+
+func (p Progress) ETA() time.Duration {
+	rate := p.Rate()
+	if rate == 0 || p.Done >= p.Total {
+		return 0
+	}
+	return time.Duration(float64(p.Total-p.Done) / rate * float64(time.Second)).Round(time.Second)
+}
+
+type ProgressFn func(Progress)
+
+// TransferOptions control how files already present at the destination are handled
+type TransferOptions struct {
+	Conflict ConflictPolicy
+	// Resolve decides conflicts when Conflict is ConflictAsk, without it such conflicts fail
+	Resolve ConflictResolver
+	// written are destination files written by previous run of the same transfer, they are resumed whatever the policy
+	written map[string]struct{}
+	// onWrite is called before destination file is written
+	onWrite func(destinationPath string)
+}
+
+// progressInterval limits how often progress is reported
+const progressInterval = 100 * time.Millisecond
+
+// TotalSize sums sizes of entries located in root including directory contents
+func TotalSize(fsys RemoteFS, root string, entries []types.Entry) (uint64, error) {
+	var total uint64
+	for _, entry := range entries {
+		if entry.Type != types.TypeDirectory {
+			total += entry.Size
+			continue
+		}
+		location := path.Join(root, entry.Name)
+		children, err := fsys.List(location)
+		if err != nil {
+			return 0, err
+		}
+		size, err := TotalSize(fsys, location, children)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// Copy copies entries from sourceRoot into destinationRoot, directories are copied with their content
+func Copy(source RemoteFS, sourceRoot string, entries []types.Entry, destination RemoteFS, destinationRoot string) error {
+	return CopyWithProgress(context.Background(), source, sourceRoot, entries, destination, destinationRoot, TransferOptions{}, nil)
+}
+
+// CopyWithProgress is Copy reporting the progress to onProgress, which may be nil,
+// the copy stops with the context error once ctx is done,
+// files are copied in parallel when source or destination is a Pool
+func CopyWithProgress(
+	ctx context.Context,
+	source RemoteFS,
+	sourceRoot string,
+	entries []types.Entry,
+	destination RemoteFS,
+	destinationRoot string,
+	opts TransferOptions,
+	onProgress ProgressFn,
+) error {
+	c := newCopier(ctx, source, destination, opts, onProgress)
+	defer c.cancel()
+	if onProgress != nil {
+		total, err := TotalSize(source, sourceRoot, entries)
+		if err != nil {
+			return err
+		}
+		c.progress.Total = total
+	}
+	return c.run(func(files chan<- fileTask) error {
+		existing, err := c.listExisting(destinationRoot)
+		if err != nil {
+			return err
+		}
+		return c.copyEntries(sourceRoot, entries, destinationRoot, existing, files)
+	})
+}
+
+// CopyTo copies entry at sourcePath to destinationPath, which may have other name, directories are copied with their content
+func CopyTo(
+	ctx context.Context,
+	source RemoteFS,
+	sourcePath string,
+	destination RemoteFS,
+	destinationPath string,
+	opts TransferOptions,
+	onProgress ProgressFn,
+) error {
+	c := newCopier(ctx, source, destination, opts, onProgress)
+	defer c.cancel()
+	entry, err := source.Stat(sourcePath)
+	if err != nil {
+		return err
+	}
+	if onProgress != nil {
+		total, err := TotalSize(source, path.Dir(sourcePath), []types.Entry{entry})
+		if err != nil {
+			return err
+		}
+		c.progress.Total = total
+	}
+	var existing *types.Entry
+	destinationEntry, err := destination.Stat(destinationPath)
+	switch {
+	case err == nil:
+		existing = &destinationEntry
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	return c.run(func(files chan<- fileTask) error {
+		return c.copyEntry(sourcePath, entry, destinationPath, existing, true, files)
+	})
+}
+
+func newCopier(ctx context.Context, source, destination RemoteFS, opts TransferOptions, onProgress ProgressFn) *copier {
+	ctx, cancel := context.WithCancel(ctx)
+	return &copier{
+		ctx:         ctx,
+		cancel:      cancel,
+		source:      source,
+		destination: destination,
+		opts:        opts,
+		onProgress:  onProgress,
+		policy:      opts.Conflict,
+		progress:    Progress{Started: time.Now()},
+	}
+}
+
+// run copies files passed by walk using workers, the first error stops the copy
+func (c *copier) run(walk func(files chan<- fileTask) error) error {
+	files := make(chan fileTask)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism(c.source, c.destination); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range files {
+				if err := c.copyFile(task); err != nil {
+					c.fail(err)
+					c.cancel()
+				}
+			}
+		}()
+	}
+	if err := walk(files); err != nil {
+		c.fail(err)
+	}
+	close(files)
+	wg.Wait()
+	if c.err != nil {
+		return c.err
+	}
+	c.report(true)
+	return nil
+}
+
+// parallelism is how many files are copied at once, pool allows as many as it has connections
+func parallelism(filesystems ...RemoteFS) int {
+	n := 1
+	for _, fsys := range filesystems {
+		if pool, ok := fsys.(*Pool); ok {
+			n = Max(n, pool.Size())
+		}
+	}
+	return n
+}
+
+type fileTask struct {
+	sourcePath      string
+	entry           types.Entry
+	destinationPath string
+	// existing is the file at destination path, nil if there is none
+	existing *types.Entry
+}
+
+// copier walks directories and creates them at the destination, files are passed to workers,
+// progress is shared by the workers so it is guarded by mu
+type copier struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	source      RemoteFS
+	destination RemoteFS
+	opts        TransferOptions
+	onProgress  ProgressFn
+	// askMu makes workers ask one at a time, so answer applied to all is used by the waiting ones
+	askMu sync.Mutex
+
+	mu sync.Mutex
+	// policy is changed when user applies answer to all conflicts
+	policy     ConflictPolicy
+	progress   Progress
+	lastReport time.Time
+	// err is the first error, the others are usually caused by cancelling after it
+	err error
+}
+
+func (c *copier) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+func (c *copier) copyEntries(
+	sourceRoot string,
+	entries []types.Entry,
+	destinationRoot string,
+	existing map[string]types.Entry,
+	files chan<- fileTask,
+) error {
+	for _, entry := range entries {
+		var destinationEntry *types.Entry
+		if existingEntry, ok := existing[entry.Name]; ok {
+			destinationEntry = &existingEntry
+		}
+		err := c.copyEntry(
+			path.Join(sourceRoot, entry.Name),
+			entry,
+			path.Join(destinationRoot, entry.Name),
+			destinationEntry,
+			existing != nil,
+			files,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyEntry passes file to workers or creates directory and walks its content,
+// existing is entry at destination path, it is known only when the destination was listed
+func (c *copier) copyEntry(
+	sourcePath string,
+	entry types.Entry,
+	destinationPath string,
+	existing *types.Entry,
+	listed bool,
+	files chan<- fileTask,
+) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	if entry.Type != types.TypeDirectory {
+		files <- fileTask{sourcePath: sourcePath, entry: entry, destinationPath: destinationPath, existing: existing}
+		return nil
+	}
+	var children map[string]types.Entry
+	switch {
+	case !listed:
+		if err := mkdirIfNotExist(c.destination, destinationPath); err != nil {
+			return err
+		}
+	case existing != nil && existing.Type == types.TypeDirectory:
+		var err error
+		if children, err = c.listExisting(destinationPath); err != nil {
+			return err
+		}
+	default:
+		if err := c.destination.Mkdir(destinationPath); err != nil {
+			return err
+		}
+		children = map[string]types.Entry{}
+	}
+	sourceChildren, err := c.source.List(sourcePath)
+	if err != nil {
+		return err
+	}
+	return c.copyEntries(sourcePath, sourceChildren, destinationPath, children, files)
+}
+
+// listExisting lists destination directory by name to find conflicts, listing is skipped when they are overwritten anyway
+func (c *copier) listExisting(location string) (map[string]types.Entry, error) {
+	if c.conflictPolicy() == ConflictOverwrite && len(c.opts.written) == 0 {
+		return nil, nil
+	}
+	entries, err := c.destination.List(location)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]types.Entry, len(entries))
+	for _, entry := range entries {
+		existing[entry.Name] = entry
+	}
+	return existing, nil
+}
+
+func (c *copier) conflictPolicy() ConflictPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy
+}
+
+func (c *copier) copyFile(task fileTask) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	destinationPath, offset, skip := task.destinationPath, uint64(0), false
+	if task.existing != nil {
+		var err error
+		if destinationPath, offset, skip, err = c.resolve(task); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	c.progress.File = task.sourcePath
+	if skip {
+		offset = task.entry.Size
+	}
+	c.progress.Done += offset
+	c.progress.Skipped += offset
+	c.reportLocked(true)
+	c.mu.Unlock()
+	if skip {
+		return nil
+	}
+	if c.opts.onWrite != nil {
+		c.opts.onWrite(destinationPath)
+	}
+	r, err := c.source.OpenFrom(task.sourcePath, offset)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := c.destination.WriteFrom(destinationPath, offset)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, &countingReader{Reader: r, copier: c}); err != nil {
+		_ = CloseWriter(w, err)
+		return err
+	}
+	return w.Close()
+}
+
+// resolve applies conflict policy to file which exists at the destination
+func (c *copier) resolve(task fileTask) (destinationPath string, offset uint64, skip bool, err error) {
+	if task.existing.Type == types.TypeDirectory {
+		return "", 0, false, fmt.Errorf("copy %s: destination %s is a directory", task.sourcePath, task.destinationPath)
+	}
+	conflict := Conflict{
+		SourcePath:      task.sourcePath,
+		Source:          task.entry,
+		DestinationPath: task.destinationPath,
+		Destination:     *task.existing,
+	}
+	policy := c.conflictPolicy()
+	if _, ok := c.opts.written[task.destinationPath]; ok {
+		policy = ConflictResume
+	}
+	if policy == ConflictAsk {
+		if policy, err = c.ask(conflict); err != nil {
+			return "", 0, false, err
+		}
+	}
+	return resolveConflict(c.destination, policy, conflict)
+}
+
+func (c *copier) ask(conflict Conflict) (ConflictPolicy, error) {
+	c.askMu.Lock()
+	defer c.askMu.Unlock()
+	if policy := c.conflictPolicy(); policy != ConflictAsk || c.opts.Resolve == nil {
+		return policy, nil
+	}
+	policy, applyToAll, err := c.opts.Resolve(c.ctx, conflict)
+	if err != nil {
+		return ConflictAsk, err
+	}
+	if applyToAll {
+		c.mu.Lock()
+		c.policy = policy
+		c.mu.Unlock()
+	}
+	return policy, nil
+}
+
+func (c *copier) report(force bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reportLocked(force)
+}
+
+func (c *copier) reportLocked(force bool) {
+	if c.onProgress == nil {
+		return
+	}
+	if !force && time.Since(c.lastReport) < progressInterval {
+		return
+	}
+	c.lastReport = time.Now()
+	c.onProgress(c.progress)
+}
+
+type countingReader struct {
+	io.Reader
+	copier *copier
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	if err := r.copier.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.Reader.Read(p)
+	r.copier.mu.Lock()
+	r.copier.progress.Done += uint64(n)
+	r.copier.reportLocked(false)
+	r.copier.mu.Unlock()
+	return n, err
+}
@@ -0,0 +1,180 @@
+package pkg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrWrongPassphrase = errors.New("wrong vault passphrase")
+
+// scrypt parameters recommended for interactive logins
+const (
+	vaultScryptN   = 1 << 15
+	vaultScryptR   = 8
+	vaultScryptP   = 1
+	vaultKeyLength = 32
+	vaultSaltSize  = 16
+)
+
+// vaultFile is the vault as stored on disk, passwords are encrypted by AES-GCM with key derived from passphrase,
+// binary values are base64 encoded
+type vaultFile struct {
+	Salt  string
+	Nonce string
+	Data  string
+}
+
+// Vault is local file with passwords encrypted by master passphrase
+type Vault struct {
+	path      string
+	salt      []byte
+	aead      cipher.AEAD
+	passwords map[string]string
+}
+
+func getVaultFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(home, ".goftp.vault"), nil
+}
+
+// VaultExists reports whether passphrase for the vault was already chosen
+func VaultExists() (bool, error) {
+	filePath, err := getVaultFilePath()
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// OpenVault decrypts the vault, new vault is encrypted by the passphrase
+func OpenVault(passphrase string) (*Vault, error) {
+	filePath, err := getVaultFilePath()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		salt := make([]byte, vaultSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		return newVault(filePath, salt, passphrase)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file vaultFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid vault file: %w", err)
+	}
+	salt, nonce, sealed, err := decodeVaultFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault file: %w", err)
+	}
+	v, err := newVault(filePath, salt, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	data, err := v.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if err := yaml.Unmarshal(data, &v.passwords); err != nil {
+		return nil, fmt.Errorf("invalid vault content: %w", err)
+	}
+	return v, nil
+}
+
+func decodeVaultFile(file vaultFile) (salt, nonce, data []byte, err error) {
+	if salt, err = base64.StdEncoding.DecodeString(file.Salt); err != nil {
+		return nil, nil, nil, err
+	}
+	if nonce, err = base64.StdEncoding.DecodeString(file.Nonce); err != nil {
+		return nil, nil, nil, err
+	}
+	if data, err = base64.StdEncoding.DecodeString(file.Data); err != nil {
+		return nil, nil, nil, err
+	}
+	return salt, nonce, data, nil
+}
+
+func newVault(filePath string, salt []byte, passphrase string) (*Vault, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, vaultScryptN, vaultScryptR, vaultScryptP, vaultKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{
+		path:      filePath,
+		salt:      salt,
+		aead:      aead,
+		passwords: map[string]string{},
+	}, nil
+}
+
+func (v *Vault) Password(conf ServerConf) (string, error) {
+	password, ok := v.passwords[conf.ID()]
+	if !ok {
+		return "", ErrNoPassword
+	}
+	return password, nil
+}
+
+func (v *Vault) SetPassword(conf ServerConf, password string) error {
+	v.passwords[conf.ID()] = password
+	return v.save()
+}
+
+func (v *Vault) DeletePassword(conf ServerConf) error {
+	delete(v.passwords, conf.ID())
+	return v.save()
+}
+
+// save encrypts the passwords with new nonce, the file is replaced at once so it is never left half written
+func (v *Vault) save() error {
+	data, err := yaml.Marshal(v.passwords)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(vaultFile{
+		Salt:  base64.StdEncoding.EncodeToString(v.salt),
+		Nonce: base64.StdEncoding.EncodeToString(nonce),
+		Data:  base64.StdEncoding.EncodeToString(v.aead.Seal(nil, nonce, data, nil)),
+	})
+	if err != nil {
+		return err
+	}
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, v.path)
+}
@@ -3,34 +3,28 @@ package screens
 import (
 	"fmt"
 
-	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type confirmation struct {
+	overlay
 	message   string
-	returnFn  returnFn
 	onQuit    func()
 	onConfirm onConfirmFn
 	isOk      bool
+	// showAllHelp toggles full help
+	showAllHelp bool
 }
 
-type onConfirmFn func() error
+// onConfirmFn returns command changing the covered screen
+type onConfirmFn func() tea.Cmd
 
 func initConfirmation(messageText string, returnTo tea.Model, returnCmd tea.Cmd, onConfirm onConfirmFn, onQuit func()) (tea.Model, tea.Cmd) {
-	rf := returnFn(func() (tea.Model, tea.Cmd) {
-		return returnTo, returnCmd
-	})
-	if returnTo == nil {
-		rf = func() (tea.Model, tea.Cmd) {
-			return nil, tea.Quit
-		}
-	}
 	return confirmation{
+		overlay:   overlay{returnTo: returnTo, returnCmd: returnCmd},
 		message:   messageText,
-		returnFn:  rf,
 		onQuit:    onQuit,
 		onConfirm: onConfirm,
 		isOk:      true,
@@ -45,25 +39,29 @@ func (m confirmation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
+		case key.Matches(msg, cKeys.Quit):
+			if m.onQuit != nil {
+				m.onQuit()
+			}
+			return m, tea.Quit
+		case key.Matches(msg, cKeys.Cancel):
+			return m.back()
+		case key.Matches(msg, cKeys.Help):
+			m.showAllHelp = !m.showAllHelp
+			return m, nil
 		case key.Matches(msg, cKeys.Switch):
 			m.isOk = !m.isOk
 			return m, nil
 		case key.Matches(msg, cKeys.Ok):
 			if m.isOk {
-				if err := m.onConfirm(); err != nil {
-					returnModel, returnCmd := m.returnFn()
-					return initMessageWithOnQuit(
-						fmt.Sprintf("Action failed: %s", err.Error()),
-						returnModel,
-						returnCmd,
-						m.onQuit,
-					)
-				}
+				return m.backWith(m.onConfirm())
 			}
-			return m.returnFn()
+			return m.back()
 		}
+		return m, nil
 	}
-	return m, nil
+	cmd := m.forward(msg)
+	return m, cmd
 }
 
 func (m confirmation) View() string {
@@ -85,30 +83,48 @@ func (m confirmation) View() string {
 			Render(m.message),
 		ok,
 		cancel,
-		help.New().View(mKeys),
+		renderHelp(cKeys, m.showAllHelp),
 	)
 }
 
 var cKeys = cKeyMap{
 	Ok: key.NewBinding(
 		key.WithKeys(tea.KeyEnter.String()),
-		key.WithHelp("enter", "return"),
+		key.WithHelp("enter", "answer"),
 	),
 	Switch: key.NewBinding(
 		key.WithKeys(tea.KeyUp.String(), "j", tea.KeyDown.String(), "k"),
 		key.WithHelp("↓/j/↑/k", "switch answer"),
 	),
+	Cancel: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "cancel"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "more help"),
+	),
 }
 
 type cKeyMap struct {
 	Ok     key.Binding
 	Switch key.Binding
+	Cancel key.Binding
+	Quit   key.Binding
+	Help   key.Binding
 }
 
 func (m cKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{m.Ok, m.Switch}
+	return []key.Binding{m.Ok, m.Switch, m.Help}
 }
 
 func (m cKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{{m.Ok, m.Switch}}
+	return [][]key.Binding{
+		{m.Switch, m.Ok},
+		{m.Cancel, m.Quit, m.Help},
+	}
 }
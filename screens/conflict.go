@@ -0,0 +1,158 @@
+package screens
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/prathoss/goftp/pkg"
+	"github.com/prathoss/goftp/types"
+)
+
+// conflictPrompt asks what to do with file which exists at the destination of running transfer
+type conflictPrompt struct {
+	overlay
+	queue      *pkg.TransferQueue
+	request    pkg.ConflictRequest
+	cursor     int
+	applyToAll bool
+	onQuit     func()
+}
+
+// cpPolicies are the answers, ask is the absence of one
+var cpPolicies = pkg.ConflictPolicies[1:]
+
+func initConflictPrompt(queue *pkg.TransferQueue, request pkg.ConflictRequest, returnTo tea.Model, onQuit func()) (tea.Model, tea.Cmd) {
+	return conflictPrompt{
+		overlay: overlay{returnTo: returnTo},
+		queue:   queue,
+		request: request,
+		onQuit:  onQuit,
+	}, nil
+}
+
+func (m conflictPrompt) Init() tea.Cmd {
+	return nil
+}
+
+func (m conflictPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, cpKeys.Quit):
+			m.onQuit()
+			return m, tea.Quit
+		case key.Matches(msg, cpKeys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, cpKeys.Down):
+			if m.cursor < len(cpPolicies)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, cpKeys.ApplyToAll):
+			m.applyToAll = !m.applyToAll
+		case key.Matches(msg, cpKeys.Ok):
+			m.queue.ResolveConflict(m.request.ID, cpPolicies[m.cursor], m.applyToAll)
+			return m.back()
+		case key.Matches(msg, cpKeys.Cancel):
+			m.queue.Cancel(m.request.JobID)
+			return m.back()
+		}
+		return m, nil
+	}
+	cmd := m.forward(msg)
+	// the job may have been stopped meanwhile
+	if request, ok := m.queue.PendingConflict(); !ok || request.ID != m.request.ID {
+		return m.returnTo, cmd
+	}
+	return m, cmd
+}
+
+func (m conflictPrompt) View() string {
+	conflict := m.request.Conflict
+	options := make([]string, 0, len(cpPolicies)+2)
+	for i, policy := range cpPolicies {
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		options = append(options, fmt.Sprintf("%s%s", cursor, policy.Description()))
+	}
+	applyToAll := "[ ]"
+	if m.applyToAll {
+		applyToAll = "[x]"
+	}
+	options = append(options, "", fmt.Sprintf("%s apply to all conflicts of this transfer", applyToAll))
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.
+			NewStyle().
+			Padding(1, 3).
+			Border(lipgloss.RoundedBorder(), true).
+			Render(lipgloss.JoinVertical(
+				lipgloss.Left,
+				fmt.Sprintf("%s already exists", conflict.DestinationPath),
+				"",
+				fmt.Sprintf("Source:      %s", cpDescribe(conflict.Source)),
+				fmt.Sprintf("Destination: %s", cpDescribe(conflict.Destination)),
+			)),
+		lipgloss.JoinVertical(lipgloss.Left, options...),
+		help.New().View(cpKeys),
+	)
+}
+
+func cpDescribe(entry types.Entry) string {
+	modified := "unknown time"
+	if !entry.ModTime.IsZero() {
+		modified = entry.ModTime.Format(time.RFC822)
+	}
+	return fmt.Sprintf("%s, %s", pkg.PrettyPrintSize(entry.Size), modified)
+}
+
+var cpKeys = cpKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys(tea.KeyUp.String(), "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys(tea.KeyDown.String(), "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	ApplyToAll: key.NewBinding(
+		key.WithKeys("a", " "),
+		key.WithHelp("a/space", "apply to all"),
+	),
+	Ok: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp("enter", "choose"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "cancel transfer"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+}
+
+type cpKeyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	ApplyToAll key.Binding
+	Ok         key.Binding
+	Cancel     key.Binding
+	Quit       key.Binding
+}
+
+func (m cpKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Up, m.Down, m.ApplyToAll, m.Ok, m.Cancel, m.Quit}
+}
+
+func (m cpKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{m.Up, m.Down, m.ApplyToAll}, {m.Ok, m.Cancel, m.Quit}}
+}
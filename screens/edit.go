@@ -0,0 +1,272 @@
+package screens
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/prathoss/goftp/components"
+	"github.com/prathoss/goftp/pkg"
+	"github.com/prathoss/goftp/types"
+)
+
+// editSession is remote file downloaded for editing, local files are edited in place and have no session
+type editSession struct {
+	// list is copy of the pane the file is edited from, so it is uploaded back to the same location
+	list  components.FileListModel
+	entry types.Entry
+	// path is the downloaded copy in own temporary directory
+	path string
+	// sum is of the downloaded content, the remote file changed meanwhile when its content differs
+	sum [sha256.Size]byte
+}
+
+type editDownloadedMsg struct {
+	session *editSession
+	err     error
+}
+
+type editFinishedMsg struct {
+	session *editSession
+	err     error
+}
+
+type editUploadedMsg struct {
+	session *editSession
+	// conflict is set when the remote file changed since it was downloaded, nothing is uploaded then
+	conflict bool
+	err      error
+}
+
+// editor is $VISUAL or $EDITOR, which may contain arguments
+func editor(file string) (*exec.Cmd, error) {
+	command := os.Getenv("VISUAL")
+	if command == "" {
+		command = os.Getenv("EDITOR")
+	}
+	if command == "" {
+		command = "vi"
+		if runtime.GOOS == "windows" {
+			command = "notepad"
+		}
+	}
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("editor is not set, set VISUAL or EDITOR")
+	}
+	return exec.Command(args[0], append(args[1:], file)...), nil
+}
+
+// edit suspends the screen while editor runs, remote file is downloaded first and edited in the copy
+func (m filesModel) edit() (tea.Model, tea.Cmd) {
+	entry, ok := m.source.Current()
+	if !ok {
+		return m, nil
+	}
+	if entry.Type == types.TypeDirectory {
+		return m.sendMessage(fmt.Sprintf("%s is a directory", entry.Name))
+	}
+	if m.source.IsLocal() {
+		return m.runEditor(filepath.Join(filepath.FromSlash(m.source.GetLocation()), entry.Name), nil)
+	}
+	list := m.source
+	return m, func() tea.Msg {
+		session, err := downloadForEdit(list, entry)
+		return editDownloadedMsg{session: session, err: err}
+	}
+}
+
+func (m filesModel) runEditor(file string, session *editSession) (tea.Model, tea.Cmd) {
+	cmd, err := editor(file)
+	if err != nil {
+		session.remove()
+		return m.sendMessage(err.Error())
+	}
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editFinishedMsg{session: session, err: err}
+	})
+}
+
+func (m filesModel) editDownloaded(msg editDownloadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m.sendMessage(fmt.Sprintf("Could not download %s: %s", msg.session.entry.Name, msg.err.Error()))
+	}
+	return m.runEditor(msg.session.path, msg.session)
+}
+
+// downloadForEdit copies the file into temporary directory, the name is kept so editor recognizes the file type,
+// the returned session holds the entry also on failure
+func downloadForEdit(list components.FileListModel, entry types.Entry) (*editSession, error) {
+	session := &editSession{list: list, entry: entry}
+	// the listing may be old, the size is compared with the one at download
+	current, err := list.Stat(entry.Name)
+	if err != nil {
+		return session, err
+	}
+	session.entry = current
+	dir, err := os.MkdirTemp("", "goftp-edit-")
+	if err != nil {
+		return session, err
+	}
+	session.path = filepath.Join(dir, entry.Name)
+	if err := session.download(); err != nil {
+		session.remove()
+		return session, err
+	}
+	return session, nil
+}
+
+func (s *editSession) download() error {
+	r, err := s.list.OpenFrom(s.entry, 0)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(s.path)
+	if err != nil {
+		_ = r.Close()
+		return err
+	}
+	s.sum, err = copySum(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// copySum copies r into w and closes r, the sum is of the copied content
+func copySum(w io.Writer, r io.ReadCloser) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	hash := sha256.New()
+	_, err := io.Copy(io.MultiWriter(w, hash), r)
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, err
+}
+
+func (s *editSession) changed() (bool, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+	return sha256.Sum256(content) != s.sum, nil
+}
+
+// remoteChanged compares the remote file with the downloaded content, the listing has only minutes on ftp
+func (s *editSession) remoteChanged() (bool, error) {
+	current, err := s.list.Stat(s.entry.Name)
+	if err != nil {
+		return false, err
+	}
+	if current.Size != s.entry.Size {
+		return true, nil
+	}
+	r, err := s.list.OpenFrom(current, 0)
+	if err != nil {
+		return false, err
+	}
+	sum, err := copySum(io.Discard, r)
+	return sum != s.sum, err
+}
+
+func (s *editSession) remove() {
+	if s != nil && s.path != "" {
+		_ = os.RemoveAll(filepath.Dir(s.path))
+	}
+}
+
+// editFinished uploads changed file, user confirms overwriting when the remote file changed meanwhile
+func (m filesModel) editFinished(msg editFinishedMsg) (tea.Model, tea.Cmd) {
+	session := msg.session
+	if msg.err != nil {
+		session.remove()
+		return m.sendMessage(fmt.Sprintf("Editor failed: %s", msg.err.Error()))
+	}
+	if session == nil {
+		if err := m.source.Refresh(); err != nil {
+			return m.sendMessage(fmt.Sprintf("Could not refresh files: %s", err.Error()))
+		}
+		return m, nil
+	}
+	changed, err := session.changed()
+	if err != nil {
+		return m.sendMessage(fmt.Sprintf("Could not read edited file %s: %s", session.path, err.Error()))
+	}
+	if !changed {
+		session.remove()
+		return m, nil
+	}
+	return m, uploadEdited(session, false)
+}
+
+// uploadEdited uploads the edited file unless the remote one changed since download and overwrite is not set
+func uploadEdited(session *editSession, overwrite bool) tea.Cmd {
+	return func() tea.Msg {
+		if !overwrite {
+			conflict, err := session.remoteChanged()
+			if err != nil || conflict {
+				return editUploadedMsg{session: session, conflict: conflict, err: err}
+			}
+		}
+		return editUploadedMsg{session: session, err: session.upload()}
+	}
+}
+
+func (s *editSession) upload() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	w, err := s.list.Create(s.entry.Name)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	_, err = io.Copy(w, f)
+	if closeErr := pkg.CloseWriter(w, err); err == nil {
+		err = closeErr
+	}
+	// the copy can not be removed while open on some systems
+	_ = f.Close()
+	return err
+}
+
+func (m filesModel) editUploaded(msg editUploadedMsg) (tea.Model, tea.Cmd) {
+	session := msg.session
+	if msg.err != nil {
+		return m.sendMessage(fmt.Sprintf("Could not upload %s, the edited file is kept in %s: %s", session.entry.Name, session.path, msg.err.Error()))
+	}
+	if msg.conflict {
+		return initConfirmation(
+			fmt.Sprintf(
+				"%s changed on the server while it was edited, overwrite it?\nThe edited file is kept in %s when not uploaded.",
+				session.entry.Name,
+				session.path,
+			),
+			m,
+			nil,
+			func() tea.Cmd {
+				return uploadEdited(session, true)
+			},
+			func() {
+				_ = m.Close()
+			},
+		)
+	}
+	session.remove()
+	if err := m.source.Refresh(); err != nil {
+		return m.sendMessage(fmt.Sprintf("Could not refresh files: %s", err.Error()))
+	}
+	if err := m.destination.Refresh(); err != nil {
+		return m.sendMessage(fmt.Sprintf("Could not refresh files: %s", err.Error()))
+	}
+	return m, nil
+}
@@ -2,106 +2,308 @@ package screens
 
 import (
 	"fmt"
-	"os"
-	"time"
+	"io"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/jlaffaye/ftp"
 	"github.com/prathoss/goftp/components"
 	"github.com/prathoss/goftp/pkg"
 	"github.com/prathoss/goftp/types"
 )
 
-type ftpModel struct {
-	client             *ftp.ServerConn
+type remoteModel struct {
+	close              func() error
 	keepAliveQuitChan  chan<- struct{}
 	keepAliveErrorChan <-chan error
-	ftpAliveError      error
+	aliveError         error
+	// lostReported is set once the user was told the browsing connection was lost while transfers were running
+	lostReported bool
 }
 
 type filesModel struct {
 	source      components.FileListModel
 	destination components.FileListModel
-	ftpModel    *ftpModel
+	remoteModel *remoteModel
+	transfer    components.TransferModel
+	// conflict is default policy for existing files
+	conflict pkg.ConflictPolicy
+	// shownConflict is id of the last conflict request shown to user, so it is not shown again
+	shownConflict int
+	width         int
+	// columns are the configured ones, detailed view shows all of them
+	columns  []components.Column
+	detailed bool
+	// conf is the connection, sort orders are remembered in it
+	conf pkg.ServerConf
+	// filtering is set while the filter of the source list is typed
+	filtering   bool
+	filterInput textinput.Model
 }
 
-func initFiles(server string, port int, user, passwd string) (tea.Model, error) {
+const (
+	// fHeaderHeight is the selected count with margin, help bar takes another line
+	fHeaderHeight = 2
+	fHelpHeight   = 1
+	// fPaneGap is margin between the lists
+	fPaneGap = 4
+)
+
+// initFiles connects to the server, remotePath overrides the initial remote directory of the connection when set
+func initFiles(conf pkg.ServerConf, passwd string, remotePath string) (tea.Model, error) {
 	// server
-	c, err := ftp.DialTimeout(fmt.Sprintf("%s:%d", server, port), 5*time.Second)
-	if err != nil {
-		return nil, err
-	}
-	err = c.Login(user, passwd)
+	remote, err := pkg.Dial(conf, passwd)
 	if err != nil {
 		return nil, err
 	}
-	ftpKeepAliveQuit, ftpKeepAliveError := pkg.KeepFtpAlive(c)
-	ftpModel := &ftpModel{
-		client:             c,
-		keepAliveQuitChan:  ftpKeepAliveQuit,
-		keepAliveErrorChan: ftpKeepAliveError,
+	// transfers get own connections so the browsing one stays free
+	pool := pkg.NewPool(func() (pkg.RemoteFS, error) {
+		return pkg.Dial(conf, passwd)
+	}, conf.TransferConnections())
+	keepAliveQuit, keepAliveError := pkg.KeepAlive(remote.NoOp)
+	remoteModel := &remoteModel{
+		close: func() error {
+			_ = pool.Close()
+			return remote.Close()
+		},
+		keepAliveQuitChan:  keepAliveQuit,
+		keepAliveErrorChan: keepAliveError,
 	}
-	// listen for possible error from keeping ftp alive
+	// listen for possible error from keeping connection alive
 	go func() {
-		ftpModel.ftpAliveError = <-ftpKeepAliveError
+		remoteModel.aliveError = <-keepAliveError
 	}()
-	serverList, err := components.InitFileListModelBuilder(server, "/", func(location string) ([]types.Entry, error) {
-		files, err := c.List(location)
-		if err != nil {
-			return nil, err
-		}
-		return pkg.MapSlice(files, pkg.FtpToEntry), nil
-	}).
-		WithTransferFn(pkg.PrepareDownloadFn(c)).
-		WithDeleteFn(pkg.PrepareFtpDeleteFn(c)).
-		Build()
+	if remotePath == "" {
+		remotePath = conf.InitialRemotePath()
+	}
+	serverList, err := components.InitFileListModel(conf.Server, remotePath, remote)
+	if err != nil {
+		_ = remoteModel.Close()
+		return nil, err
+	}
+	serverList.SetTransferFS(pool)
 
 	// local
-	dir, err := os.Getwd()
+	dir, err := conf.InitialLocalPath()
+	if err != nil {
+		_ = remoteModel.Close()
+		return nil, err
+	}
+	localList, err := components.InitFileListModel("Local", dir, pkg.LocalFS{})
 	if err != nil {
+		_ = remoteModel.Close()
 		return nil, err
 	}
-	localList, err := components.InitFileListModelBuilder("Local", dir, func(location string) ([]types.Entry, error) {
-		files, err := os.ReadDir(location)
-		if err != nil {
+
+	columns := components.DefaultColumns
+	if cfg, err := pkg.GetConfig(); err == nil && len(cfg.Columns) > 0 {
+		if columns, err = components.ParseColumns(cfg.Columns); err != nil {
+			_ = remoteModel.Close()
 			return nil, err
 		}
-		return pkg.MapSlice(files, pkg.OsToEntry), nil
-	}).
-		WithTransferFn(pkg.PrepareUploadFn(c)).
-		WithDeleteFn(pkg.OsDeleteFn).
-		Build()
+	}
 
-	return filesModel{
+	m := filesModel{
 		source:      localList,
 		destination: serverList,
-		ftpModel:    ftpModel,
-	}, nil
+		remoteModel: remoteModel,
+		transfer:    components.InitTransferModel(pkg.NewTransferQueue()),
+		conflict:    conf.Conflict,
+		columns:     columns,
+		conf:        conf,
+		filterInput: textinput.New(),
+	}
+	m.source.SetSortOrder(conf.LocalSort)
+	m.destination.SetSortOrder(conf.RemoteSort)
+	m.setColumns(columns)
+	m.layout(terminalSize())
+	return m, nil
 }
 
-func (m filesModel) Init() tea.Cmd {
+// fActionMsg changes the files screen after a dialog closed. Dialogs send it instead of changing
+// the screen they cover, as the covered screen is replaced with every message forwarded to it.
+type fActionMsg struct {
+	action func(m *filesModel) error
+	// failure starts the message shown when the action fails
+	failure string
+}
+
+func fAction(failure string, action func(m *filesModel) error) tea.Cmd {
+	return func() tea.Msg {
+		return fActionMsg{action: action, failure: failure}
+	}
+}
+
+// rememberSortOrder saves sort order of the panel with the connection, the order is used for the session
+// also when the connection was changed or removed in the meantime and it can not be saved
+func (m *filesModel) rememberSortOrder(local bool, order pkg.SortOrder) error {
+	conf := m.conf
+	if local {
+		conf.LocalSort = order
+	} else {
+		conf.RemoteSort = order
+	}
+	_, err := pkg.UpdateConfig(func(cfg *pkg.Conf) error {
+		return cfg.UpdateServer(m.conf, conf)
+	})
+	if err != nil {
+		return err
+	}
+	m.conf = conf
 	return nil
 }
 
+// startFiltering edits filter of the source list, the list is narrowed as the pattern is typed
+func (m *filesModel) startFiltering() tea.Cmd {
+	m.filtering = true
+	m.filterInput.SetValue(m.source.Filter().Pattern)
+	m.filterInput.CursorEnd()
+	m.updateFilterPrompt()
+	return m.filterInput.Focus()
+}
+
+func (m *filesModel) stopFiltering() {
+	m.filtering = false
+	m.filterInput.Blur()
+}
+
+func (m *filesModel) updateFilterPrompt() {
+	m.filterInput.Prompt = fmt.Sprintf("/(%s) ", m.source.Filter().Mode)
+}
+
+// updateFilter handles keys while the filter is typed
+func (m filesModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	filter := m.source.Filter()
+	switch {
+	case key.Matches(msg, ffKeys.Quit):
+		_ = m.Close()
+		return m, tea.Quit
+	case key.Matches(msg, ffKeys.Apply):
+		m.stopFiltering()
+		return m, nil
+	case key.Matches(msg, ffKeys.Clear):
+		m.stopFiltering()
+		m.source.SetFilter(pkg.Filter{Mode: filter.Mode})
+		return m, nil
+	case key.Matches(msg, ffKeys.Mode):
+		filter.Mode = filter.Mode.Next()
+		m.source.SetFilter(filter)
+		m.updateFilterPrompt()
+		return m, nil
+	case key.Matches(msg, ffKeys.Down):
+		m.source.Down()
+		return m, nil
+	case key.Matches(msg, ffKeys.Up):
+		m.source.Up()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	filter.Pattern = m.filterInput.Value()
+	m.source.SetFilter(filter)
+	return m, cmd
+}
+
+func (m *filesModel) setColumns(columns []components.Column) {
+	m.source.SetColumns(columns)
+	m.destination.SetColumns(columns)
+}
+
+// layout splits the terminal between the lists, the transfer progress keeps its space when idle
+func (m *filesModel) layout(width, height int) {
+	m.width = width
+	paneWidth := (width - fPaneGap) / 2
+	paneHeight := height - fHeaderHeight - components.TransferViewHeight - fHelpHeight
+	m.source.SetSize(paneWidth, paneHeight)
+	m.destination.SetSize(paneWidth, paneHeight)
+	m.transfer.SetWidth(width)
+}
+
+func (r *remoteModel) Close() error {
+	select {
+	case r.keepAliveQuitChan <- struct{}{}:
+	default:
+	}
+	return r.close()
+}
+
+func (m filesModel) Init() tea.Cmd {
+	return m.transfer.Listen()
+}
+
 func (m filesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if err := m.ftpModel.ftpAliveError; err != nil {
-		cfg, _ := pkg.GetConfig()
+	if err := m.remoteModel.aliveError; err != nil {
+		// transfers run on own connections of the pool, the screen is left once they are finished
+		if m.transfer.Queue().Drained() {
+			_ = m.Close()
+			cfg, _ := pkg.GetConfig()
 
-		return initMessage(
-			fmt.Sprintf("Connection with server lost: %s", err.Error()),
-			InitSavedConnections(cfg.Servers),
-			nil,
-		)
+			return initMessage(
+				fmt.Sprintf("Connection with server lost: %s", err.Error()),
+				InitSavedConnections(cfg.Servers),
+				nil,
+			)
+		}
+		if !m.remoteModel.lostReported {
+			m.remoteModel.lostReported = true
+			return m.sendMessage(fmt.Sprintf("Connection with server lost: %s, transfers continue", err.Error()))
+		}
 	}
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.layout(msg.Width, msg.Height)
+		return m, nil
+	case fActionMsg:
+		if err := msg.action(&m); err != nil {
+			return m.sendMessage(fmt.Sprintf("%s: %s", msg.failure, err.Error()))
+		}
+		return m, nil
+	case editDownloadedMsg:
+		return m.editDownloaded(msg)
+	case editFinishedMsg:
+		return m.editFinished(msg)
+	case editUploadedMsg:
+		return m.editUploaded(msg)
+	case components.TransferQueueMsg:
+		var cmd tea.Cmd
+		m.transfer, cmd = m.transfer.Update(msg)
+		if request, ok := m.transfer.Queue().PendingConflict(); ok && request.ID != m.shownConflict {
+			m.shownConflict = request.ID
+			model, _ := initConflictPrompt(m.transfer.Queue(), request, m, func() {
+				_ = m.Close()
+			})
+			return model, cmd
+		}
+		if !msg.Finished {
+			return m, cmd
+		}
+		// panes may have been switched while transferring
+		if err := m.source.Refresh(); err != nil {
+			return m.sendMessage(fmt.Sprintf("Could not refresh files: %s", err.Error()))
+		}
+		if err := m.destination.Refresh(); err != nil {
+			return m.sendMessage(fmt.Sprintf("Could not refresh files: %s", err.Error()))
+		}
+		return m, cmd
 	case tea.KeyMsg:
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
 		switch {
 		case key.Matches(msg, fKeys.Quit):
+			if !m.transfer.Queue().Drained() {
+				return initDrainingQueue(m.transfer.Queue(), m, func() {
+					_ = m.Close()
+				})
+			}
 			_ = m.Close()
 			return m, tea.Quit
+		case key.Matches(msg, fKeys.Queue):
+			return initQueue(m.transfer.Queue(), m, func() {
+				_ = m.Close()
+			})
 		case key.Matches(msg, fKeys.Down):
 			m.source.Down()
 		case key.Matches(msg, fKeys.Up):
@@ -119,30 +321,198 @@ func (m filesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		case key.Matches(msg, fKeys.Transfer):
-			if err := m.source.Transfer(m.destination.GetLocation()); err != nil {
-				return m.sendMessage(fmt.Sprintf("Could not transfer files: %s", err.Error()))
-			}
-			if err := m.destination.Refresh(); err != nil {
-				return m.sendMessage(fmt.Sprintf("Could not refresh files: %s", err.Error()))
-			}
+			m.source.EnqueueTransfer(m.transfer.Queue(), m.destination, pkg.TransferOptions{Conflict: m.conflict})
 			m.source.DeselectAll()
+		case key.Matches(msg, fKeys.Mirror):
+			// planning only reads the locations, they do not change while the plan is shown
+			source, destination := m.source, m.destination
+			return initMirror(
+				func(opts pkg.MirrorOptions) (pkg.MirrorPlan, error) {
+					return source.PlanMirror(destination, opts)
+				},
+				func(plan pkg.MirrorPlan) tea.Cmd {
+					// deletes are queued too, the lists are refreshed once the queue finishes
+					return fAction("Mirror failed", func(m *filesModel) error {
+						plan.Enqueue(m.transfer.Queue())
+						return nil
+					})
+				},
+				m,
+				func() {
+					_ = m.Close()
+				},
+			)
 		case key.Matches(msg, fKeys.Switch):
 			m.source, m.destination = m.destination, m.source
+		case key.Matches(msg, fKeys.Filter):
+			return m, m.startFiltering()
+		case key.Matches(msg, fKeys.NextMatch):
+			m.source.NextMatch()
+		case key.Matches(msg, fKeys.PreviousMatch):
+			m.source.PreviousMatch()
+		case key.Matches(msg, fKeys.ClearFilter):
+			m.source.SetFilter(pkg.Filter{Mode: m.source.Filter().Mode})
+		case key.Matches(msg, fKeys.Sort):
+			return initSortMenu(m.source.SortOrder(), m, func(order pkg.SortOrder) tea.Cmd {
+				return fAction("Could not remember sort order", func(m *filesModel) error {
+					m.source.SetSortOrder(order)
+					return m.rememberSortOrder(m.source.IsLocal(), order)
+				})
+			}, func() {
+				_ = m.Close()
+			})
+		case key.Matches(msg, fKeys.Details):
+			m.detailed = !m.detailed
+			if m.detailed {
+				m.setColumns(components.Columns)
+			} else {
+				m.setColumns(m.columns)
+			}
 		case key.Matches(msg, fKeys.ToggleSelection):
 			m.source.ToggleSelection()
+		case key.Matches(msg, fKeys.SelectAll):
+			m.source.SelectAll()
+		case key.Matches(msg, fKeys.DeselectAll):
+			m.source.DeselectAll()
+		case key.Matches(msg, fKeys.InvertSelection):
+			m.source.InvertSelection()
+		case key.Matches(msg, fKeys.SelectUp):
+			m.source.SelectUp()
+		case key.Matches(msg, fKeys.SelectDown):
+			m.source.SelectDown()
+		case key.Matches(msg, fKeys.SelectPattern), key.Matches(msg, fKeys.DeselectPattern):
+			selected := key.Matches(msg, fKeys.SelectPattern)
+			action := "select"
+			if !selected {
+				action = "deselect"
+			}
+			return initInput(
+				fmt.Sprintf("Glob pattern of names to %s, regular expression between slashes like /\\.log$/:", action),
+				"",
+				m,
+				nil,
+				func(pattern string) tea.Cmd {
+					return fAction("Action failed", func(m *filesModel) error {
+						match, err := pkg.ParseNamePattern(pattern)
+						if err != nil {
+							return err
+						}
+						m.source.SelectMatching(match, selected)
+						return nil
+					})
+				},
+			)
+		case key.Matches(msg, fKeys.Rename):
+			entry, ok := m.source.Current()
+			if !ok {
+				return m, nil
+			}
+			return initInput("New name, path relative to the directory moves the entry:", entry.Name, m, nil, func(name string) tea.Cmd {
+				return fAction("Action failed", func(m *filesModel) error {
+					if err := m.source.Rename(name); err != nil {
+						return err
+					}
+					return m.destination.Refresh()
+				})
+			})
+		case key.Matches(msg, fKeys.BulkRename):
+			return initInput(
+				"Rename selected entries, template with {name}, {base}, {ext}, {n} or {n:3} counter, or s/regexp/replacement/:",
+				"{name}",
+				m,
+				nil,
+				func(expression string) tea.Cmd {
+					return fAction("Action failed", func(m *filesModel) error {
+						if err := m.source.RenameSelected(expression); err != nil {
+							return err
+						}
+						return m.destination.Refresh()
+					})
+				},
+			)
+		case key.Matches(msg, fKeys.Move):
+			return initInput("Move selected entries to directory:", m.source.GetLocation(), m, nil, func(destination string) tea.Cmd {
+				return fAction("Action failed", func(m *filesModel) error {
+					if err := m.source.MoveSelected(destination); err != nil {
+						return err
+					}
+					return m.destination.Refresh()
+				})
+			})
+		case key.Matches(msg, fKeys.MakeDir):
+			return initInput("Name of the new directory, missing parents are created:", "", m, nil, func(name string) tea.Cmd {
+				return fAction("Action failed", func(m *filesModel) error {
+					if err := m.source.MakeDir(name); err != nil {
+						return err
+					}
+					return m.destination.Refresh()
+				})
+			})
+		case key.Matches(msg, fKeys.CreateFile):
+			return initInput("Name of the new empty file:", "", m, nil, func(name string) tea.Cmd {
+				return fAction("Action failed", func(m *filesModel) error {
+					if err := m.source.CreateFile(name); err != nil {
+						return err
+					}
+					return m.destination.Refresh()
+				})
+			})
+		case key.Matches(msg, fKeys.View):
+			entry, ok := m.source.Current()
+			if !ok {
+				return m, nil
+			}
+			if entry.Type == types.TypeDirectory {
+				return m.sendMessage(fmt.Sprintf("%s is a directory", entry.Name))
+			}
+			source := m.source
+			return initViewer(entry.Name, entry.Size, func(offset uint64) (io.ReadCloser, error) {
+				return source.OpenFrom(entry, offset)
+			}, m, func() {
+				_ = m.Close()
+			})
+		case key.Matches(msg, fKeys.Edit):
+			return m.edit()
+		case key.Matches(msg, fKeys.Chmod):
+			entries := m.source.SelectedOrCurrent()
+			if len(entries) == 0 {
+				return m, nil
+			}
+			mode, err := m.source.EntryMode(entries[0])
+			return initPermissions(entries, mode, err, m, func(change pkg.ModeChange, recursive bool) tea.Cmd {
+				return fAction("Could not change permissions", func(m *filesModel) error {
+					if err := m.source.Chmod(change, recursive); err != nil {
+						return err
+					}
+					return m.destination.Refresh()
+				})
+			}, func() {
+				_ = m.Close()
+			})
 		case key.Matches(msg, fKeys.Delete):
 			return initConfirmation(fmt.Sprintf("Realy want to delete %d files", m.source.GetSelectedCount()),
-				&m,
+				m,
 				nil,
-				m.source.Delete,
+				func() tea.Cmd {
+					return fAction("Action failed", func(m *filesModel) error {
+						return m.source.Delete()
+					})
+				},
 				func() {
 					_ = m.Close()
 				},
 			)
 		case key.Matches(msg, fKeys.Help):
-			// TODO: implement
+			return initHelp("Files", fKeys, fHelpCategories, m, func() {
+				_ = m.Close()
+			})
 		}
 	}
+	if m.filtering {
+		var cmd tea.Cmd
+		m.filterInput, cmd = m.filterInput.Update(msg)
+		return m, cmd
+	}
 	return m, nil
 }
 
@@ -158,26 +528,43 @@ func (m filesModel) sendMessage(message string) (tea.Model, tea.Cmd) {
 }
 
 func (m filesModel) View() string {
+	bottom := fHelp(m.width).View(fKeys)
+	if m.filtering {
+		bottom = lipgloss.JoinHorizontal(lipgloss.Top, m.filterInput.View(), "  ", fHelp(m.width).View(ffKeys))
+	}
 	return lipgloss.JoinVertical(
 		lipgloss.Center,
 		lipgloss.NewStyle().
 			Margin(0, 0, 1).
-			Render(fmt.Sprintf("Number of selected files: %d", m.source.GetSelectedCount())),
+			Render(fmt.Sprintf(
+				"Number of selected files: %d, size: %s",
+				m.source.GetSelectedCount(),
+				pkg.PrettyPrintSize(m.source.GetSelectedSize()),
+			)),
 		lipgloss.JoinHorizontal(
 			lipgloss.Top,
 			m.source.View(true),
 			lipgloss.NewStyle().
-				Margin(0, 2).
+				Width(fPaneGap).
 				Render(""),
 			m.destination.View(false),
 		),
-		help.New().View(fKeys),
+		lipgloss.NewStyle().
+			Height(components.TransferViewHeight).
+			Render(m.transfer.View()),
+		bottom,
 	)
 }
 
+func fHelp(width int) help.Model {
+	h := help.New()
+	h.Width = width
+	return h
+}
+
 func (m filesModel) Close() error {
-	m.ftpModel.keepAliveQuitChan <- struct{}{}
-	return m.ftpModel.client.Quit()
+	m.transfer.Queue().Close()
+	return m.remoteModel.Close()
 }
 
 var fKeys = fKeyMap{
@@ -213,10 +600,102 @@ var fKeys = fKeyMap{
 		key.WithKeys(" "),
 		key.WithHelp("space", "toggle selection"),
 	),
+	SelectAll: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "select all"),
+	),
+	DeselectAll: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "deselect all"),
+	),
+	InvertSelection: key.NewBinding(
+		key.WithKeys("*"),
+		key.WithHelp("*", "invert selection"),
+	),
+	SelectPattern: key.NewBinding(
+		key.WithKeys("+"),
+		key.WithHelp("+", "select by pattern"),
+	),
+	DeselectPattern: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "deselect by pattern"),
+	),
+	SelectUp: key.NewBinding(
+		key.WithKeys(tea.KeyShiftUp.String(), "K"),
+		key.WithHelp("shift+↑/K", "select up"),
+	),
+	SelectDown: key.NewBinding(
+		key.WithKeys(tea.KeyShiftDown.String(), "J"),
+		key.WithHelp("shift+↓/J", "select down"),
+	),
 	Delete: key.NewBinding(
 		key.WithKeys("d"),
 		key.WithHelp("d", "delete"),
 	),
+	MakeDir: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "new directory"),
+	),
+	CreateFile: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "new file"),
+	),
+	View: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "view"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit"),
+	),
+	Chmod: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "permissions"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rename"),
+	),
+	BulkRename: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "rename selected"),
+	),
+	Move: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "move"),
+	),
+	Queue: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "transfer queue"),
+	),
+	Mirror: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "mirror directory"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "sort"),
+	),
+	Details: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "toggle details"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	PreviousMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous match"),
+	),
+	ClearFilter: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "clear filter"),
+	),
 	Help: key.NewBinding(
 		key.WithKeys("?"),
 		key.WithHelp("?", "help"),
@@ -232,7 +711,30 @@ type fKeyMap struct {
 	Transfer        key.Binding
 	Switch          key.Binding
 	ToggleSelection key.Binding
+	SelectAll       key.Binding
+	DeselectAll     key.Binding
+	InvertSelection key.Binding
+	SelectPattern   key.Binding
+	DeselectPattern key.Binding
+	SelectUp        key.Binding
+	SelectDown      key.Binding
 	Delete          key.Binding
+	MakeDir         key.Binding
+	CreateFile      key.Binding
+	View            key.Binding
+	Edit            key.Binding
+	Chmod           key.Binding
+	Rename          key.Binding
+	BulkRename      key.Binding
+	Move            key.Binding
+	Queue           key.Binding
+	Mirror          key.Binding
+	Sort            key.Binding
+	Details         key.Binding
+	Filter          key.Binding
+	NextMatch       key.Binding
+	PreviousMatch   key.Binding
+	ClearFilter     key.Binding
 	Help            key.Binding
 }
 
@@ -240,9 +742,61 @@ func (f fKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{f.Up, f.Down, f.Enter, f.Return, f.Quit, f.Help}
 }
 
+// fHelpCategories name the columns of full help
+var fHelpCategories = []string{"Navigation", "Selection", "Files", "View", "Search", "General"}
+
 func (f fKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{f.Up, f.Down, f.Enter, f.Return, f.Quit},
-		{f.ToggleSelection, f.Transfer, f.Switch, f.Delete},
+		{f.Up, f.Down, f.Enter, f.Return, f.Switch},
+		{f.ToggleSelection, f.SelectUp, f.SelectDown, f.SelectAll, f.DeselectAll, f.InvertSelection, f.SelectPattern, f.DeselectPattern},
+		{f.Transfer, f.View, f.Edit, f.Delete, f.MakeDir, f.CreateFile, f.Rename, f.BulkRename, f.Move, f.Chmod, f.Mirror, f.Queue},
+		{f.Sort, f.Details},
+		{f.Filter, f.NextMatch, f.PreviousMatch, f.ClearFilter},
+		{f.Help, f.Quit},
 	}
 }
+
+// ffKeys are used while the filter is typed
+var ffKeys = ffKeyMap{
+	Apply: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp("enter", "keep filter"),
+	),
+	Clear: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "clear"),
+	),
+	Mode: key.NewBinding(
+		key.WithKeys(tea.KeyTab.String()),
+		key.WithHelp("tab", "mode"),
+	),
+	Up: key.NewBinding(
+		key.WithKeys(tea.KeyUp.String()),
+		key.WithHelp("↑", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys(tea.KeyDown.String()),
+		key.WithHelp("↓", "down"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+}
+
+type ffKeyMap struct {
+	Apply key.Binding
+	Clear key.Binding
+	Mode  key.Binding
+	Up    key.Binding
+	Down  key.Binding
+	Quit  key.Binding
+}
+
+func (f ffKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{f.Apply, f.Clear, f.Mode}
+}
+
+func (f ffKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{f.Apply, f.Clear, f.Mode, f.Up, f.Down, f.Quit}}
+}
@@ -0,0 +1,151 @@
+package screens
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// helpScreen lists all key bindings of a screen, columns of the full help are the categories
+type helpScreen struct {
+	overlay
+	title    string
+	viewport viewport.Model
+	onQuit   func()
+}
+
+var (
+	hsTitleStyle    = lipgloss.NewStyle().Bold(true).Margin(0, 0, 1)
+	hsCategoryStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+	hsKeyStyle      = lipgloss.NewStyle().Width(14).Foreground(lipgloss.AdaptiveColor{Light: "#909090", Dark: "#626262"})
+)
+
+func initHelp(title string, keyMap help.KeyMap, categories []string, returnTo tea.Model, onQuit func()) (tea.Model, tea.Cmd) {
+	width, height := terminalSize()
+	m := helpScreen{
+		overlay:  overlay{returnTo: returnTo},
+		title:    title,
+		viewport: viewport.New(width, 0),
+		onQuit:   onQuit,
+	}
+	m.resize(width, height)
+	m.viewport.SetContent(hsContent(keyMap, categories))
+	return m, nil
+}
+
+func hsContent(keyMap help.KeyMap, categories []string) string {
+	var b strings.Builder
+	for i, column := range keyMap.FullHelp() {
+		category := "Other"
+		if i < len(categories) {
+			category = categories[i]
+		}
+		if i > 0 {
+			b.WriteRune('\n')
+		}
+		b.WriteString(hsCategoryStyle.Render(category))
+		b.WriteRune('\n')
+		for _, binding := range column {
+			if !binding.Enabled() {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("  %s%s\n", hsKeyStyle.Render(binding.Help().Key), binding.Help().Desc))
+		}
+	}
+	return b.String()
+}
+
+// resize leaves space for the title and help bar
+func (m *helpScreen) resize(width, height int) {
+	m.viewport.Width = width
+	m.viewport.Height = height - lipgloss.Height(hsTitleStyle.Render(m.title)) - 1
+}
+
+func (m helpScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (m helpScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, hsKeys.Quit):
+			if m.onQuit != nil {
+				m.onQuit()
+			}
+			return m, tea.Quit
+		case key.Matches(msg, hsKeys.Back):
+			return m.back()
+		}
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+	}
+	cmd := m.forward(msg)
+	return m, cmd
+}
+
+func (m helpScreen) View() string {
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		hsTitleStyle.Render(m.title),
+		m.viewport.View(),
+		help.New().View(hsKeys),
+	)
+}
+
+var hsKeys = hsKeyMap{
+	Scroll: key.NewBinding(
+		key.WithKeys(tea.KeyUp.String(), "k", tea.KeyDown.String(), "j", tea.KeyPgUp.String(), tea.KeyPgDown.String()),
+		key.WithHelp("↑/k/↓/j/pgup/pgdown", "scroll"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String(), "?", "q"),
+		key.WithHelp("esc/?/q", "close help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+}
+
+type hsKeyMap struct {
+	Scroll key.Binding
+	Back   key.Binding
+	Quit   key.Binding
+}
+
+func (m hsKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Scroll, m.Back, m.Quit}
+}
+
+func (m hsKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{m.Scroll, m.Back, m.Quit}}
+}
+
+// terminalSize is asked for, because window size message is sent only on start and resize
+func terminalSize() (width, height int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 80, 24
+	}
+	return width, height
+}
+
+// renderHelp renders short help, or full help when showAll is set
+func renderHelp(keyMap help.KeyMap, showAll bool) string {
+	h := help.New()
+	h.ShowAll = showAll
+	// full help is cut at the width
+	h.Width, _ = terminalSize()
+	return h.View(keyMap)
+}
@@ -0,0 +1,95 @@
+package screens
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// input asks for a single line of text
+type input struct {
+	overlay
+	message string
+	input   textinput.Model
+	// onSubmit returns command changing returnTo
+	onSubmit func(value string) tea.Cmd
+}
+
+func initInput(messageText string, value string, returnTo tea.Model, returnCmd tea.Cmd, onSubmit func(value string) tea.Cmd) (tea.Model, tea.Cmd) {
+	in := textinput.New()
+	in.SetValue(value)
+	in.CursorEnd()
+	in.Focus()
+	return input{
+		overlay:  overlay{returnTo: returnTo, returnCmd: returnCmd},
+		message:  messageText,
+		input:    in,
+		onSubmit: onSubmit,
+	}, textinput.Blink
+}
+
+func (m input) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m input) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, iKeys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, iKeys.Cancel):
+			return m.back()
+		case key.Matches(msg, iKeys.Ok):
+			return m.backWith(m.onSubmit(m.input.Value()))
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, tea.Batch(cmd, m.forward(msg))
+}
+
+func (m input) View() string {
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.
+			NewStyle().
+			Padding(1, 3).
+			Border(lipgloss.RoundedBorder(), true).
+			Render(lipgloss.JoinVertical(lipgloss.Left, m.message, "", m.input.View())),
+		help.New().View(iKeys),
+	)
+}
+
+var iKeys = iKeyMap{
+	Ok: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp("enter", "ok"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "cancel"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+}
+
+type iKeyMap struct {
+	Ok     key.Binding
+	Cancel key.Binding
+	Quit   key.Binding
+}
+
+func (m iKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Ok, m.Cancel, m.Quit}
+}
+
+func (m iKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{m.Ok, m.Cancel, m.Quit}}
+}
@@ -1,66 +1,220 @@
 package screens
 
 import (
+	"errors"
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/prathoss/goftp/pkg"
 )
 
 type loginModel struct {
-	server         textinput.Model
-	port           textinput.Model
-	user           textinput.Model
-	password       textinput.Model
+	inputs         []textinput.Model
 	selectedCursor uint8
+	// saved is the connection the screen was opened with
+	saved pkg.ServerConf
+	// remotePath overrides initial remote directory of the connection without saving it
+	remotePath string
+	// showAllHelp toggles full help
+	showAllHelp bool
 }
 
+// vault is unlocked once per session, so the passphrase is not asked for each connection
+var vault *pkg.Vault
+
 const (
 	lmServerInput uint8 = iota
 	lmPortInput
 	lmUserInput
 	lmPasswdInput
+	lmRememberInput
+	lmVaultPassphraseInput
+	lmProtocolInput
+	lmTLSModeInput
+	lmCAFileInput
+	lmCertFileInput
+	lmKeyFileInput
+	lmInsecureInput
+	lmSshKeyFileInput
+	lmDataModeInput
+	lmTransferTypeInput
+	lmTimeoutInput
+	lmConnectionsInput
+	lmConflictInput
+	lmRemotePathInput
+	lmLocalPathInput
+	lmNameInput
+	lmGroupInput
+	lmInputCount
 )
 
+const (
+	lmNo  = "no"
+	lmYes = "yes"
+)
+
+// lmChoices are inputs which are not typed into, their value is cycled with left/right
+var lmChoices = map[uint8][]string{
+	lmProtocolInput:     pkg.MapSlice(pkg.Protocols, pkg.Protocol.String),
+	lmTLSModeInput:      pkg.MapSlice(pkg.TLSModes, pkg.TLSMode.String),
+	lmInsecureInput:     {lmNo, lmYes},
+	lmConflictInput:     pkg.MapSlice(pkg.ConflictPolicies, pkg.ConflictPolicy.String),
+	lmDataModeInput:     pkg.MapSlice(pkg.DataModes, pkg.DataMode.String),
+	lmTransferTypeInput: pkg.MapSlice(pkg.TransferTypes, pkg.TransferType.String),
+	lmRememberInput:     pkg.MapSlice(pkg.PasswordStores, pkg.PasswordStore.String),
+}
+
 func InitLoginModel() (tea.Model, tea.Cmd) {
-	server := textinput.New()
-	server.Placeholder = "Server url"
-	server.Focus()
+	inputs := make([]textinput.Model, lmInputCount)
+	for i := range inputs {
+		inputs[i] = textinput.New()
+	}
+
+	inputs[lmServerInput].Placeholder = "Server url"
+	inputs[lmServerInput].Focus()
+
+	inputs[lmPortInput].Placeholder = "Port (default by protocol)"
+
+	inputs[lmUserInput].Placeholder = "User"
+
+	inputs[lmPasswdInput].Placeholder = "Password"
+	inputs[lmPasswdInput].EchoMode = textinput.EchoPassword
+	inputs[lmPasswdInput].EchoCharacter = '*'
+
+	inputs[lmRememberInput].Prompt = "Remember password (←/→): "
+	inputs[lmRememberInput].SetValue(pkg.PasswordStoreNone.String())
+
+	inputs[lmVaultPassphraseInput].Placeholder = "Vault passphrase (when remembered in vault)"
+	if exists, _ := pkg.VaultExists(); !exists {
+		inputs[lmVaultPassphraseInput].Placeholder = "New vault passphrase (when remembered in vault)"
+	}
+	inputs[lmVaultPassphraseInput].EchoMode = textinput.EchoPassword
+	inputs[lmVaultPassphraseInput].EchoCharacter = '*'
+
+	inputs[lmProtocolInput].Prompt = "Protocol (←/→): "
+	inputs[lmProtocolInput].SetValue(pkg.ProtocolFtp.String())
+
+	inputs[lmTLSModeInput].Prompt = "TLS mode (←/→): "
+	inputs[lmTLSModeInput].SetValue(pkg.TLSModeNone.String())
+
+	inputs[lmCAFileInput].Placeholder = "CA bundle file (optional)"
+	inputs[lmCertFileInput].Placeholder = "Client certificate file (optional)"
+	inputs[lmKeyFileInput].Placeholder = "Client key file (optional)"
 
-	port := textinput.New()
-	port.Placeholder = "Port"
+	inputs[lmInsecureInput].Prompt = "Skip TLS verification (←/→): "
+	inputs[lmInsecureInput].SetValue(lmNo)
 
-	user := textinput.New()
-	user.Placeholder = "User"
+	inputs[lmSshKeyFileInput].Placeholder = "SSH private key file (optional)"
 
-	passwd := textinput.New()
-	passwd.Placeholder = "Password"
-	passwd.EchoMode = textinput.EchoPassword
-	passwd.EchoCharacter = '*'
+	inputs[lmDataModeInput].Prompt = "Data connection (←/→): "
+	inputs[lmDataModeInput].SetValue(pkg.DataModePassive.String())
+
+	inputs[lmTransferTypeInput].Prompt = "Transfer type (←/→): "
+	inputs[lmTransferTypeInput].SetValue(pkg.TransferTypeBinary.String())
+
+	inputs[lmTimeoutInput].Placeholder = fmt.Sprintf("Timeout in seconds (default %d)", pkg.DefaultTimeout)
+
+	inputs[lmConnectionsInput].Placeholder = fmt.Sprintf(
+		"Transfer connections %d-%d (default %d)",
+		pkg.MinConnections,
+		pkg.MaxConnections,
+		pkg.DefaultConnections,
+	)
+
+	inputs[lmConflictInput].Prompt = "When file exists (←/→): "
+	inputs[lmConflictInput].SetValue(pkg.ConflictAsk.String())
+
+	inputs[lmRemotePathInput].Placeholder = "Initial remote directory (default /)"
+	inputs[lmLocalPathInput].Placeholder = "Initial local directory (default working directory)"
+
+	inputs[lmNameInput].Placeholder = "Name (optional)"
+	inputs[lmGroupInput].Placeholder = "Group (optional)"
 
 	return loginModel{
-		server:         server,
-		port:           port,
-		user:           user,
-		password:       passwd,
+		inputs:         inputs,
 		selectedCursor: 0,
 	}, textinput.Blink
 }
 
-func InitLoginModelWithValues(server string, port int, user string) (tea.Model, tea.Cmd) {
+func InitLoginModelWithValues(conf pkg.ServerConf) (tea.Model, tea.Cmd) {
 	model, cmd := InitLoginModel()
 	loginModel := model.(loginModel)
-	loginModel.server.SetValue(server)
-	loginModel.server.Blur()
-	loginModel.port.SetValue(strconv.Itoa(port))
-	loginModel.port.Blur()
-	loginModel.user.SetValue(user)
-	loginModel.user.Blur()
-	loginModel.password.Focus()
+	loginModel.inputs[lmServerInput].SetValue(conf.Server)
+	loginModel.inputs[lmPortInput].SetValue(strconv.Itoa(conf.Port))
+	loginModel.inputs[lmUserInput].SetValue(conf.User)
+	loginModel.inputs[lmProtocolInput].SetValue(conf.Protocol.String())
+	loginModel.inputs[lmTLSModeInput].SetValue(conf.TLS.Mode.String())
+	loginModel.inputs[lmCAFileInput].SetValue(conf.TLS.CAFile)
+	loginModel.inputs[lmCertFileInput].SetValue(conf.TLS.CertFile)
+	loginModel.inputs[lmKeyFileInput].SetValue(conf.TLS.KeyFile)
+	if conf.TLS.InsecureSkipVerify {
+		loginModel.inputs[lmInsecureInput].SetValue(lmYes)
+	}
+	loginModel.inputs[lmSshKeyFileInput].SetValue(conf.KeyFile)
+	if conf.Connections != 0 {
+		loginModel.inputs[lmConnectionsInput].SetValue(strconv.Itoa(conf.Connections))
+	}
+	loginModel.inputs[lmConflictInput].SetValue(conf.Conflict.String())
+	loginModel.inputs[lmRememberInput].SetValue(conf.PasswordStore.String())
+	loginModel.inputs[lmDataModeInput].SetValue(conf.DataMode.String())
+	loginModel.inputs[lmTransferTypeInput].SetValue(conf.TransferType.String())
+	if conf.Timeout != 0 {
+		loginModel.inputs[lmTimeoutInput].SetValue(strconv.Itoa(conf.Timeout))
+	}
+	loginModel.inputs[lmRemotePathInput].SetValue(conf.RemotePath)
+	loginModel.inputs[lmLocalPathInput].SetValue(conf.LocalPath)
+	loginModel.inputs[lmNameInput].SetValue(conf.Name)
+	loginModel.inputs[lmGroupInput].SetValue(conf.Group)
+	if conf.PasswordStore != pkg.PasswordStoreNone {
+		loginModel.inputs[lmPasswdInput].Placeholder = "Password (remembered, leave empty)"
+	}
+	loginModel.saved = conf
 	loginModel.selectedCursor = lmPasswdInput
+	if conf.PasswordStore == pkg.PasswordStoreVault && vault == nil {
+		loginModel.selectedCursor = lmVaultPassphraseInput
+	}
+	loginModel.blurUnselected()
+	loginModel.focusInput()
+	return loginModel, cmd
+}
+
+// InitConnection connects to the connection, login screen asks for credentials which are missing,
+// remotePath overrides the initial remote directory when set
+func InitConnection(conf pkg.ServerConf, passwd string, remotePath string) (tea.Model, tea.Cmd) {
+	model, cmd := InitLoginModelWithValues(conf)
+	login := model.(loginModel)
+	login.remotePath = remotePath
+	login.inputs[lmPasswdInput].SetValue(passwd)
+	switch {
+	case conf.User == "":
+		login.selectedCursor = lmUserInput
+	case passwd != "" || conf.PasswordStore == pkg.PasswordStoreSecretService || conf.PasswordStore == pkg.PasswordStoreVault && vault != nil:
+		return login.connect()
+	case conf.Protocol == pkg.ProtocolSftp && conf.PasswordStore == pkg.PasswordStoreNone:
+		// ssh agent or key may be enough, password is asked when it is not
+		return login.connect()
+	case conf.PasswordStore == pkg.PasswordStoreNone:
+		login.selectedCursor = lmPasswdInput
+	}
+	// otherwise vault passphrase or password is focused already
+	login.blurUnselected()
+	login.focusInput()
+	return login, cmd
+}
+
+// initEditLoginModel opens saved connection for editing, it is saved by ctrl+s
+func initEditLoginModel(conf pkg.ServerConf) (tea.Model, tea.Cmd) {
+	model, cmd := InitLoginModelWithValues(conf)
+	loginModel := model.(loginModel)
+	loginModel.selectedCursor = lmServerInput
+	loginModel.blurUnselected()
+	loginModel.focusInput()
 	return loginModel, cmd
 }
 
@@ -70,44 +224,53 @@ func (l loginModel) Init() tea.Cmd {
 
 func (l loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case lmHostKeyTrustedMsg:
+		if msg.err != nil {
+			return initMessage(fmt.Sprintf("Could not trust host key: %s", msg.err.Error()), l, textinput.Blink)
+		}
+		return l.connect()
 	case tea.KeyMsg:
+		// vault passphrase is hidden once the vault is unlocked, keys must not go to it
+		if !l.shown(l.selectedCursor) {
+			l.moveCursor(-1)
+		}
+		// "?" is typed into inputs
+		if key.Matches(msg, lmKeys.Help) {
+			l.showAllHelp = !l.showAllHelp
+			return l, nil
+		}
 		switch msg.Type {
 		case tea.KeyCtrlC:
 			return l, tea.Quit
-		case tea.KeyEnter:
-			// on enter login to server and move to next screen
-			port, err := strconv.Atoi(l.port.Value())
+		case tea.KeyEsc:
+			return initSavedConnectionsFromConfig()
+		case tea.KeyCtrlS:
+			// save without connecting
+			conf, err := l.serverConf()
 			if err != nil {
-				return initMessage("Port must be only numeric", l, textinput.Blink)
+				return initMessage(fmt.Sprintf("Invalid connection settings: %s", err.Error()), l, textinput.Blink)
 			}
-			files, err := initFiles(l.server.Value(), port, l.user.Value(), l.password.Value())
+			passwd, err := l.password(conf)
 			if err != nil {
-				return initMessage(fmt.Sprintf("Could not login to server: %s", err.Error()), l, textinput.Blink)
+				return initMessage(fmt.Sprintf("Could not get remembered password: %s", err.Error()), l, textinput.Blink)
 			}
-			if err := pkg.AddToConfig(pkg.ServerConf{
-				Server: l.server.Value(),
-				Port:   port,
-				User:   l.user.Value(),
-			}); err != nil {
-				return initMessage(fmt.Sprintf("Could not save connection: %s", err.Error()), files, nil)
+			if err := l.save(conf, passwd); err != nil {
+				return initMessage(fmt.Sprintf("Could not save connection: %s", err.Error()), l, textinput.Blink)
 			}
-			return files, nil
+			return initSavedConnectionsFromConfig()
+		case tea.KeyEnter:
+			return l.connect()
 		case tea.KeyTab, tea.KeyDown:
-			if l.selectedCursor < lmPasswdInput {
-				l.selectedCursor++
-				l.blurUnselected()
-				cmd := l.focusInput()
-				return l, cmd
-			}
-			return l, nil
+			cmd := l.moveCursor(1)
+			return l, cmd
 		case tea.KeyShiftTab, tea.KeyUp:
-			if l.selectedCursor > 0 {
-				l.selectedCursor--
-				l.blurUnselected()
-				cmd := l.focusInput()
-				return l, cmd
+			cmd := l.moveCursor(-1)
+			return l, cmd
+		case tea.KeyLeft, tea.KeyRight:
+			if choices, ok := lmChoices[l.selectedCursor]; ok {
+				l.cycleChoice(choices, msg.Type == tea.KeyRight)
+				return l, nil
 			}
-			return l, nil
 		}
 	}
 
@@ -115,78 +278,384 @@ func (l loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return l, cmd
 }
 
+// connect logs in to server and moves to files screen, the connection is saved
+func (l loginModel) connect() (tea.Model, tea.Cmd) {
+	conf, err := l.serverConf()
+	if err != nil {
+		return initMessage(fmt.Sprintf("Invalid connection settings: %s", err.Error()), l, textinput.Blink)
+	}
+	passwd, err := l.password(conf)
+	if err != nil {
+		return initMessage(fmt.Sprintf("Could not get remembered password: %s", err.Error()), l, textinput.Blink)
+	}
+	files, err := initFiles(conf, passwd, l.remotePath)
+	var unknownKey *pkg.UnknownHostKeyError
+	if errors.As(err, &unknownKey) {
+		return l.confirmHostKey(unknownKey)
+	}
+	if err != nil {
+		return initMessage(fmt.Sprintf("Could not login to server: %s", err.Error()), l, textinput.Blink)
+	}
+	if err := l.save(conf, passwd); err != nil {
+		message, _ := initMessage(fmt.Sprintf("Could not save connection: %s", err.Error()), files, nil)
+		return message, files.Init()
+	}
+	return files, files.Init()
+}
+
+// lmHostKeyTrustedMsg connects again after the host key was trusted
+type lmHostKeyTrustedMsg struct {
+	err error
+}
+
+// confirmHostKey shows fingerprint of the key of host connected for the first time, the key is trusted
+// only when the user confirms it
+func (l loginModel) confirmHostKey(unknownKey *pkg.UnknownHostKeyError) (tea.Model, tea.Cmd) {
+	model, cmd := initConfirmation(
+		fmt.Sprintf(
+			"Authenticity of host %s can not be established.\n%s key fingerprint is %s.\nTrust the key and connect?",
+			unknownKey.Host,
+			unknownKey.Key.Type(),
+			unknownKey.Fingerprint(),
+		),
+		l,
+		textinput.Blink,
+		func() tea.Cmd {
+			return func() tea.Msg {
+				return lmHostKeyTrustedMsg{err: pkg.TrustHostKey(unknownKey)}
+			}
+		},
+		nil,
+	)
+	confirm := model.(confirmation)
+	// trusting has to be chosen
+	confirm.isOk = false
+	return confirm, cmd
+}
+
+func (l loginModel) serverConf() (pkg.ServerConf, error) {
+	protocol, err := pkg.ParseProtocol(l.inputs[lmProtocolInput].Value())
+	if err != nil {
+		return pkg.ServerConf{}, err
+	}
+	tlsMode, err := pkg.ParseTLSMode(l.inputs[lmTLSModeInput].Value())
+	if err != nil {
+		return pkg.ServerConf{}, err
+	}
+	conflict, err := pkg.ParseConflictPolicy(l.inputs[lmConflictInput].Value())
+	if err != nil {
+		return pkg.ServerConf{}, err
+	}
+	passwordStore, err := pkg.ParsePasswordStore(l.inputs[lmRememberInput].Value())
+	if err != nil {
+		return pkg.ServerConf{}, err
+	}
+	dataMode, err := pkg.ParseDataMode(l.inputs[lmDataModeInput].Value())
+	if err != nil {
+		return pkg.ServerConf{}, err
+	}
+	transferType, err := pkg.ParseTransferType(l.inputs[lmTransferTypeInput].Value())
+	if err != nil {
+		return pkg.ServerConf{}, err
+	}
+	conf := pkg.ServerConf{
+		Protocol:      protocol,
+		Server:        l.inputs[lmServerInput].Value(),
+		User:          l.inputs[lmUserInput].Value(),
+		Conflict:      conflict,
+		PasswordStore: passwordStore,
+		Name:          strings.TrimSpace(l.inputs[lmNameInput].Value()),
+		Group:         strings.TrimSpace(l.inputs[lmGroupInput].Value()),
+		RemotePath:    strings.TrimSpace(l.inputs[lmRemotePathInput].Value()),
+		LocalPath:     strings.TrimSpace(l.inputs[lmLocalPathInput].Value()),
+		// not on the form, remembered in files screen
+		LocalSort:  l.saved.LocalSort,
+		RemoteSort: l.saved.RemoteSort,
+	}
+	if conf.RemotePath != "" && !path.IsAbs(conf.RemotePath) {
+		return pkg.ServerConf{}, errors.New("initial remote directory must be absolute")
+	}
+	switch protocol {
+	case pkg.ProtocolSftp:
+		conf.KeyFile = l.inputs[lmSshKeyFileInput].Value()
+	default:
+		conf.TLS = pkg.TLSConf{
+			Mode:               tlsMode,
+			CAFile:             l.inputs[lmCAFileInput].Value(),
+			CertFile:           l.inputs[lmCertFileInput].Value(),
+			KeyFile:            l.inputs[lmKeyFileInput].Value(),
+			InsecureSkipVerify: l.inputs[lmInsecureInput].Value() == lmYes,
+		}
+		conf.DataMode = dataMode
+		conf.TransferType = transferType
+	}
+	conf.Port = conf.DefaultPort()
+	if portValue := l.inputs[lmPortInput].Value(); portValue != "" {
+		conf.Port, err = strconv.Atoi(portValue)
+		if err != nil {
+			return pkg.ServerConf{}, errors.New("port must be only numeric")
+		}
+	}
+	if timeoutValue := l.inputs[lmTimeoutInput].Value(); timeoutValue != "" {
+		conf.Timeout, err = strconv.Atoi(timeoutValue)
+		if err != nil {
+			return pkg.ServerConf{}, errors.New("timeout must be only numeric")
+		}
+	}
+	if connectionsValue := l.inputs[lmConnectionsInput].Value(); connectionsValue != "" {
+		conf.Connections, err = strconv.Atoi(connectionsValue)
+		if err != nil {
+			return pkg.ServerConf{}, errors.New("transfer connections must be only numeric")
+		}
+		if conf.Connections < pkg.MinConnections || conf.Connections > pkg.MaxConnections {
+			return pkg.ServerConf{}, fmt.Errorf(
+				"transfer connections must be between %d and %d",
+				pkg.MinConnections,
+				pkg.MaxConnections,
+			)
+		}
+	}
+	return conf, nil
+}
+
+// password returns the typed password, if there is none the one remembered for the saved connection
+func (l loginModel) password(conf pkg.ServerConf) (string, error) {
+	passwd := l.inputs[lmPasswdInput].Value()
+	if passwd != "" || conf.PasswordStore == pkg.PasswordStoreNone || l.saved.PasswordStore == pkg.PasswordStoreNone {
+		return passwd, nil
+	}
+	secrets, err := l.secrets(l.saved.PasswordStore)
+	if err != nil {
+		return "", err
+	}
+	return secrets.Password(l.saved)
+}
+
+// save stores the connection in place of the saved one and remembers its password
+func (l loginModel) save(conf pkg.ServerConf, passwd string) error {
+	if err := l.rememberPassword(conf, passwd); err != nil {
+		return fmt.Errorf("could not remember password: %w", err)
+	}
+	_, err := pkg.UpdateConfig(func(cfg *pkg.Conf) error {
+		cfg.SaveServer(l.saved, conf)
+		return nil
+	})
+	return err
+}
+
+// rememberPassword stores the password, password remembered in other store or for other address before is moved
+func (l loginModel) rememberPassword(conf pkg.ServerConf, passwd string) error {
+	moved := l.saved.PasswordStore != pkg.PasswordStoreNone &&
+		(l.saved.PasswordStore != conf.PasswordStore || l.saved.ID() != conf.ID())
+	if moved {
+		secrets, err := l.secrets(l.saved.PasswordStore)
+		if err != nil {
+			return err
+		}
+		if err := secrets.DeletePassword(l.saved); err != nil && !errors.Is(err, pkg.ErrNoPassword) {
+			return err
+		}
+	}
+	typed := l.inputs[lmPasswdInput].Value() != ""
+	if conf.PasswordStore == pkg.PasswordStoreNone || passwd == "" || !typed && !moved {
+		return nil
+	}
+	secrets, err := l.secrets(conf.PasswordStore)
+	if err != nil {
+		return err
+	}
+	return secrets.SetPassword(conf, passwd)
+}
+
+func (l loginModel) secrets(store pkg.PasswordStore) (pkg.Secrets, error) {
+	switch store {
+	case pkg.PasswordStoreSecretService:
+		if !pkg.SecretServiceAvailable() {
+			return nil, errors.New("secret service is not available")
+		}
+		return pkg.SecretService{}, nil
+	case pkg.PasswordStoreVault:
+		if vault != nil {
+			return vault, nil
+		}
+		passphrase := l.inputs[lmVaultPassphraseInput].Value()
+		if passphrase == "" {
+			return nil, errors.New("vault passphrase is required")
+		}
+		v, err := pkg.OpenVault(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		vault = v
+		return vault, nil
+	default:
+		return nil, fmt.Errorf("unknown password store %q", store)
+	}
+}
+
+func (l *loginModel) cycleChoice(choices []string, forward bool) {
+	input := l.getSelectedInput()
+	current := 0
+	for i, choice := range choices {
+		if choice == input.Value() {
+			current = i
+			break
+		}
+	}
+	if forward {
+		current = (current + 1) % len(choices)
+	} else {
+		current = (current + len(choices) - 1) % len(choices)
+	}
+	input.SetValue(choices[current])
+}
+
 func (l *loginModel) updateInput(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if l.selectedCursor == lmPortInput && msg.Runes != nil && !pkg.IsMsgNumeric(msg) {
+		numeric := l.selectedCursor == lmPortInput || l.selectedCursor == lmConnectionsInput || l.selectedCursor == lmTimeoutInput
+		if numeric && msg.Runes != nil && !pkg.IsMsgNumeric(msg) {
+			return nil
+		}
+		if _, ok := lmChoices[l.selectedCursor]; ok {
 			return nil
 		}
 	}
 
 	m, cmd := l.getSelectedInput().Update(msg)
-	l.setSelectedInput(m)
+	l.inputs[l.selectedCursor] = m
 	return cmd
 }
 
-func (l *loginModel) focusInput() tea.Cmd {
-	return l.getSelectedInput().Focus()
+// moveCursor focuses the next shown input in direction of step, the cursor stays at the first and the last one
+func (l *loginModel) moveCursor(step int) tea.Cmd {
+	for i := int(l.selectedCursor) + step; i >= 0 && i < int(lmInputCount); i += step {
+		if l.shown(uint8(i)) {
+			l.selectedCursor = uint8(i)
+			l.blurUnselected()
+			return l.focusInput()
+		}
+	}
+	return nil
 }
 
-func (l *loginModel) getSelectedInput() *textinput.Model {
-	switch l.selectedCursor {
-	case lmServerInput:
-		return &l.server
-	case lmPortInput:
-		return &l.port
-	case lmUserInput:
-		return &l.user
+// shown reports whether the input applies to the chosen protocol, tls mode and password store
+func (l loginModel) shown(input uint8) bool {
+	ftp := l.inputs[lmProtocolInput].Value() == pkg.ProtocolFtp.String()
+	switch input {
+	case lmVaultPassphraseInput:
+		return vault == nil && l.inputs[lmRememberInput].Value() == pkg.PasswordStoreVault.String()
+	case lmTLSModeInput, lmDataModeInput, lmTransferTypeInput:
+		return ftp
+	case lmCAFileInput, lmCertFileInput, lmKeyFileInput, lmInsecureInput:
+		return ftp && l.inputs[lmTLSModeInput].Value() != pkg.TLSModeNone.String()
+	case lmSshKeyFileInput:
+		return !ftp
 	default:
-		return &l.password
+		return true
 	}
 }
 
-func (l *loginModel) blurUnselected() {
-	inputs := l.getUnselectedInputs()
-	for i := 0; i < len(inputs); i++ {
-		inputs[i].Blur()
-	}
+func (l *loginModel) focusInput() tea.Cmd {
+	return l.getSelectedInput().Focus()
 }
 
-func (l *loginModel) getUnselectedInputs() []*textinput.Model {
-	switch l.selectedCursor {
-	case lmServerInput:
-		return []*textinput.Model{&l.port, &l.user, &l.password}
-	case lmPortInput:
-		return []*textinput.Model{&l.server, &l.user, &l.password}
-	case lmUserInput:
-		return []*textinput.Model{&l.server, &l.port, &l.password}
-	default:
-		return []*textinput.Model{&l.server, &l.port, &l.user}
-	}
+func (l *loginModel) getSelectedInput() *textinput.Model {
+	return &l.inputs[l.selectedCursor]
 }
 
-func (l *loginModel) setSelectedInput(model textinput.Model) {
-	switch l.selectedCursor {
-	case lmServerInput:
-		l.server = model
-	case lmPortInput:
-		l.port = model
-	case lmUserInput:
-		l.user = model
-	default:
-		l.password = model
+func (l *loginModel) blurUnselected() {
+	for i := range l.inputs {
+		if uint8(i) != l.selectedCursor {
+			l.inputs[i].Blur()
+		}
 	}
 }
 
+// View scrolls the inputs around the selected one when they do not fit the terminal
 func (l loginModel) View() string {
+	helpView := renderHelp(lmKeys, l.showAllHelp)
+	shown := make([]uint8, 0, lmInputCount)
+	selected := 0
+	for i := uint8(0); i < lmInputCount; i++ {
+		if i == l.selectedCursor {
+			selected = len(shown)
+		}
+		if l.shown(i) {
+			shown = append(shown, i)
+		}
+	}
+	_, height := terminalSize()
+	// title and help take the rest
+	rows := pkg.Max(1, height-1-lipgloss.Height(helpView))
+	top := 0
+	if len(shown) > rows {
+		top = pkg.Min(pkg.Max(0, selected-rows/2), len(shown)-rows)
+	}
 	var b strings.Builder
 	b.WriteString("Log in:\n")
-	inputs := []textinput.Model{l.server, l.port, l.user, l.password}
-	for i, input := range inputs {
-		b.WriteString(input.View())
-		if i < len(inputs)-1 {
-			b.WriteRune('\n')
-		}
+	for _, input := range shown[top:pkg.Min(top+rows, len(shown))] {
+		b.WriteString(l.inputs[input].View())
+		b.WriteRune('\n')
 	}
+	b.WriteString(helpView)
 	return b.String()
 }
+
+var lmKeys = lmKeyMap{
+	Next: key.NewBinding(
+		key.WithKeys(tea.KeyTab.String(), tea.KeyDown.String()),
+		key.WithHelp("tab/↓", "next field"),
+	),
+	Previous: key.NewBinding(
+		key.WithKeys(tea.KeyShiftTab.String(), tea.KeyUp.String()),
+		key.WithHelp("shift+tab/↑", "previous field"),
+	),
+	Choice: key.NewBinding(
+		key.WithKeys(tea.KeyLeft.String(), tea.KeyRight.String()),
+		key.WithHelp("←/→", "change choice"),
+	),
+	Connect: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp("enter", "connect and save"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlS.String()),
+		key.WithHelp("ctrl+s", "save without connecting"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "saved connections"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("alt+?"),
+		key.WithHelp("alt+?", "more help"),
+	),
+}
+
+type lmKeyMap struct {
+	Next     key.Binding
+	Previous key.Binding
+	Choice   key.Binding
+	Connect  key.Binding
+	Save     key.Binding
+	Back     key.Binding
+	Quit     key.Binding
+	Help     key.Binding
+}
+
+func (m lmKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Connect, m.Save, m.Back, m.Quit, m.Help}
+}
+
+func (m lmKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{m.Next, m.Previous, m.Choice},
+		{m.Connect, m.Save, m.Back},
+		{m.Quit, m.Help},
+	}
+}
@@ -1,19 +1,49 @@
 package screens
 
 import (
-	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type message struct {
-	message  string
-	returnFn returnFn
-	onQuit   func()
+	overlay
+	message string
+	onQuit  func()
+	// showAllHelp toggles full help
+	showAllHelp bool
 }
 
-type returnFn func() (tea.Model, tea.Cmd)
+// overlay is a screen shown over returnTo, messages other than keys are passed to returnTo,
+// so background work of the covered screen keeps running
+type overlay struct {
+	returnTo  tea.Model
+	returnCmd tea.Cmd
+}
+
+// back returns to the covered screen, if there is none the bubbletea will quit
+func (o overlay) back() (tea.Model, tea.Cmd) {
+	if o.returnTo == nil {
+		return nil, tea.Quit
+	}
+	return o.returnTo, o.returnCmd
+}
+
+// backWith returns to the covered screen and runs cmd, dialogs change the covered screen by messages of cmd,
+// closures would change a stale copy as the screen is replaced by every message forwarded to it
+func (o overlay) backWith(cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	model, returnCmd := o.back()
+	return model, tea.Batch(returnCmd, cmd)
+}
+
+func (o *overlay) forward(msg tea.Msg) tea.Cmd {
+	if o.returnTo == nil {
+		return nil
+	}
+	var cmd tea.Cmd
+	o.returnTo, cmd = o.returnTo.Update(msg)
+	return cmd
+}
 
 // initMessage creates a model with message, if returnTo is nil, the bubbletea will quit
 func initMessage(messageText string, returnTo tea.Model, returnCmd tea.Cmd) (tea.Model, tea.Cmd) {
@@ -21,18 +51,10 @@ func initMessage(messageText string, returnTo tea.Model, returnCmd tea.Cmd) (tea
 }
 
 func initMessageWithOnQuit(messageText string, returnTo tea.Model, returnCmd tea.Cmd, onQuit func()) (tea.Model, tea.Cmd) {
-	rf := returnFn(func() (tea.Model, tea.Cmd) {
-		return returnTo, returnCmd
-	})
-	if returnTo == nil {
-		rf = func() (tea.Model, tea.Cmd) {
-			return nil, tea.Quit
-		}
-	}
 	return message{
-		message:  messageText,
-		returnFn: rf,
-		onQuit:   onQuit,
+		overlay: overlay{returnTo: returnTo, returnCmd: returnCmd},
+		message: messageText,
+		onQuit:  onQuit,
 	}, nil
 }
 
@@ -50,10 +72,14 @@ func (m message) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, tea.Quit
 		case key.Matches(msg, mKeys.Ok):
-			return m.returnFn()
+			return m.back()
+		case key.Matches(msg, mKeys.Help):
+			m.showAllHelp = !m.showAllHelp
 		}
+		return m, nil
 	}
-	return m, nil
+	cmd := m.forward(msg)
+	return m, cmd
 }
 
 func (m message) View() string {
@@ -64,7 +90,7 @@ func (m message) View() string {
 			Padding(2, 5).
 			Border(lipgloss.RoundedBorder(), true).
 			Render(m.message),
-		help.New().View(mKeys),
+		renderHelp(mKeys, m.showAllHelp),
 	)
 }
 
@@ -77,17 +103,25 @@ var mKeys = mKeyMap{
 		key.WithKeys(tea.KeyCtrlC.String(), "q"),
 		key.WithHelp("ctrl+c/q", "quit"),
 	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "more help"),
+	),
 }
 
 type mKeyMap struct {
 	Ok   key.Binding
 	Quit key.Binding
+	Help key.Binding
 }
 
 func (m mKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{m.Ok, m.Quit}
+	return []key.Binding{m.Ok, m.Quit, m.Help}
 }
 
 func (m mKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{{m.Ok, m.Quit}}
+	return [][]key.Binding{
+		{m.Ok},
+		{m.Quit, m.Help},
+	}
 }
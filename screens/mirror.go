@@ -0,0 +1,214 @@
+package screens
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/prathoss/goftp/pkg"
+	"github.com/prathoss/goftp/types"
+)
+
+// mirrorStepsInView is number of plan steps shown at once
+const mirrorStepsInView = 15
+
+type planMirrorFn func(opts pkg.MirrorOptions) (pkg.MirrorPlan, error)
+
+// mirrorModel shows mirror plan, nothing is changed until it is executed
+type mirrorModel struct {
+	overlay
+	plan   pkg.MirrorPlan
+	opts   pkg.MirrorOptions
+	replan planMirrorFn
+	// plans counts started plannings, result of older one than the last is dropped
+	plans    int
+	planning bool
+	// onExecute returns command changing returnTo
+	onExecute func(plan pkg.MirrorPlan) tea.Cmd
+	onQuit    func()
+	top       int
+}
+
+func initMirror(replan planMirrorFn, onExecute func(plan pkg.MirrorPlan) tea.Cmd, returnTo tea.Model, onQuit func()) (tea.Model, tea.Cmd) {
+	m := mirrorModel{
+		overlay:   overlay{returnTo: returnTo},
+		replan:    replan,
+		onExecute: onExecute,
+		onQuit:    onQuit,
+	}
+	cmd := m.startPlan(pkg.MirrorOptions{})
+	return m, cmd
+}
+
+type miPlannedMsg struct {
+	planned int
+	plan    pkg.MirrorPlan
+	err     error
+}
+
+// startPlan compares the directories in background, both trees are listed recursively
+func (m *mirrorModel) startPlan(opts pkg.MirrorOptions) tea.Cmd {
+	m.plans++
+	m.planning = true
+	m.opts, m.top = opts, 0
+	planned, replan := m.plans, m.replan
+	return func() tea.Msg {
+		plan, err := replan(opts)
+		return miPlannedMsg{planned: planned, plan: plan, err: err}
+	}
+}
+
+func (m mirrorModel) planned(msg miPlannedMsg) (tea.Model, tea.Cmd) {
+	m.planning = false
+	if msg.err == nil {
+		m.plan = msg.plan
+		return m, nil
+	}
+	returnTo := tea.Model(m)
+	if msg.planned == 1 {
+		// there is no plan to show
+		returnTo = m.returnTo
+	}
+	return initMessageWithOnQuit(fmt.Sprintf("Could not compare directories: %s", msg.err.Error()), returnTo, nil, m.onQuit)
+}
+
+func (m mirrorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m mirrorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, miKeys.Quit):
+			m.onQuit()
+			return m, tea.Quit
+		case key.Matches(msg, miKeys.Back):
+			return m.back()
+		case key.Matches(msg, miKeys.Down):
+			if m.top < len(m.plan.Steps)-mirrorStepsInView {
+				m.top++
+			}
+		case key.Matches(msg, miKeys.Up):
+			if m.top > 0 {
+				m.top--
+			}
+		case key.Matches(msg, miKeys.DeleteExtraneous):
+			opts := m.opts
+			opts.DeleteExtraneous = !opts.DeleteExtraneous
+			cmd := m.startPlan(opts)
+			return m, cmd
+		case key.Matches(msg, miKeys.Execute):
+			if m.planning {
+				return m, nil
+			}
+			return m.backWith(m.onExecute(m.plan))
+		}
+		return m, nil
+	case miPlannedMsg:
+		if msg.planned != m.plans {
+			return m, nil
+		}
+		return m.planned(msg)
+	}
+	cmd := m.forward(msg)
+	return m, cmd
+}
+
+func (m mirrorModel) View() string {
+	lines := []string{"Comparing directories...", ""}
+	if !m.planning {
+		lines = m.planLines()
+	}
+	deleteExtraneous := "off"
+	if m.opts.DeleteExtraneous {
+		deleteExtraneous = "on"
+	}
+	lines = append(lines, "", fmt.Sprintf("Delete extraneous: %s", deleteExtraneous))
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.NewStyle().
+			Padding(0, 1).
+			Border(lipgloss.RoundedBorder(), true).
+			Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		help.New().View(miKeys),
+	)
+}
+
+// planLines are the counts of actions and visible part of the steps
+func (m mirrorModel) planLines() []string {
+	lines := []string{
+		fmt.Sprintf(
+			"Mirror %s -> %s",
+			m.plan.SourceRoot,
+			m.plan.DestinationRoot,
+		),
+		fmt.Sprintf(
+			"copy: %d  update: %d  delete: %d  skip: %d",
+			m.plan.Count(pkg.MirrorCopy),
+			m.plan.Count(pkg.MirrorUpdate),
+			m.plan.Count(pkg.MirrorDelete),
+			m.plan.Count(pkg.MirrorSkip),
+		),
+		"",
+	}
+	if len(m.plan.Steps) == 0 {
+		lines = append(lines, "Directories are in sync")
+	}
+	for i := m.top; i < pkg.Min(m.top+mirrorStepsInView, len(m.plan.Steps)); i++ {
+		step := m.plan.Steps[i]
+		name := step.Path
+		if step.Entry.Type == types.TypeDirectory {
+			name = fmt.Sprintf("%s/", path.Clean(name))
+		}
+		lines = append(lines, fmt.Sprintf("%-6s %s", step.Action, name))
+	}
+	return lines
+}
+
+var miKeys = miKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys(tea.KeyUp.String(), "k"),
+		key.WithHelp("↑/k", "scroll up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys(tea.KeyDown.String(), "j"),
+		key.WithHelp("↓/j", "scroll down"),
+	),
+	DeleteExtraneous: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "toggle delete extraneous"),
+	),
+	Execute: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp("enter", "execute"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "cancel"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+}
+
+type miKeyMap struct {
+	Up               key.Binding
+	Down             key.Binding
+	DeleteExtraneous key.Binding
+	Execute          key.Binding
+	Back             key.Binding
+	Quit             key.Binding
+}
+
+func (m miKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Up, m.Down, m.DeleteExtraneous, m.Execute, m.Back, m.Quit}
+}
+
+func (m miKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{m.Up, m.Down, m.DeleteExtraneous}, {m.Execute, m.Back, m.Quit}}
+}
@@ -0,0 +1,189 @@
+package screens
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/prathoss/goftp/pkg"
+	"github.com/prathoss/goftp/types"
+)
+
+// permissions edits mode of entries, the new mode is previewed on the first of them
+type permissions struct {
+	overlay
+	entries []types.Entry
+	// current is mode of the first entry, it is unknown when currentErr is set
+	current    os.FileMode
+	currentErr error
+	input      textinput.Model
+	hasDirs    bool
+	recursive  bool
+	// onApply returns command changing returnTo
+	onApply func(change pkg.ModeChange, recursive bool) tea.Cmd
+	onQuit  func()
+	// showAllHelp toggles full help
+	showAllHelp bool
+}
+
+func initPermissions(
+	entries []types.Entry,
+	current os.FileMode,
+	currentErr error,
+	returnTo tea.Model,
+	onApply func(change pkg.ModeChange, recursive bool) tea.Cmd,
+	onQuit func(),
+) (tea.Model, tea.Cmd) {
+	in := textinput.New()
+	in.Prompt = "Mode: "
+	in.Placeholder = "755 or u+x,go-w"
+	if currentErr == nil {
+		in.SetValue(fmt.Sprintf("%03o", current))
+		in.CursorEnd()
+	}
+	in.Focus()
+	m := permissions{
+		overlay:    overlay{returnTo: returnTo},
+		entries:    entries,
+		current:    current,
+		currentErr: currentErr,
+		input:      in,
+		onApply:    onApply,
+		onQuit:     onQuit,
+	}
+	for _, entry := range entries {
+		if entry.Type == types.TypeDirectory {
+			m.hasDirs = true
+		}
+	}
+	return m, textinput.Blink
+}
+
+func (m permissions) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m permissions) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, pKeys.Quit):
+			if m.onQuit != nil {
+				m.onQuit()
+			}
+			return m, tea.Quit
+		case key.Matches(msg, pKeys.Cancel):
+			return m.back()
+		case key.Matches(msg, pKeys.Help):
+			m.showAllHelp = !m.showAllHelp
+			return m, nil
+		case key.Matches(msg, pKeys.Recursive):
+			m.recursive = m.hasDirs && !m.recursive
+			return m, nil
+		case key.Matches(msg, pKeys.Apply):
+			change, err := pkg.ParseModeChange(m.input.Value())
+			if err != nil {
+				return initMessageWithOnQuit(fmt.Sprintf("Could not change permissions: %s", err.Error()), m, nil, m.onQuit)
+			}
+			return m.backWith(m.onApply(change, m.recursive))
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, tea.Batch(cmd, m.forward(msg))
+}
+
+func (m permissions) View() string {
+	title := fmt.Sprintf("Permissions of %d entries", len(m.entries))
+	if len(m.entries) == 1 {
+		title = fmt.Sprintf("Permissions of %s", m.entries[0].Name)
+	}
+	current := fmt.Sprintf("Current: %s", pFormat(m.current))
+	if m.currentErr != nil {
+		current = fmt.Sprintf("Current: unknown, %s", m.currentErr.Error())
+	}
+	if len(m.entries) > 1 {
+		current = fmt.Sprintf("%s (%s)", current, m.entries[0].Name)
+	}
+	lines := []string{title, "", current, m.input.View(), m.preview()}
+	if m.hasDirs {
+		checkbox := "[ ]"
+		if m.recursive {
+			checkbox = "[x]"
+		}
+		lines = append(lines, fmt.Sprintf("%s apply to content of directories", checkbox))
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.
+			NewStyle().
+			Padding(1, 3).
+			Border(lipgloss.RoundedBorder(), true).
+			Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		renderHelp(pKeys, m.showAllHelp),
+	)
+}
+
+// preview shows the new mode of the first entry
+func (m permissions) preview() string {
+	change, err := pkg.ParseModeChange(m.input.Value())
+	switch {
+	case err != nil:
+		return fmt.Sprintf("New: %s", err.Error())
+	case change.Relative() && m.currentErr != nil:
+		return "New: depends on current permissions"
+	default:
+		return fmt.Sprintf("New: %s", pFormat(change.Apply(m.current, m.entries[0].Type == types.TypeDirectory)))
+	}
+}
+
+func pFormat(mode os.FileMode) string {
+	return fmt.Sprintf("%s (%03o)", mode.Perm().String()[1:], mode.Perm())
+}
+
+var pKeys = pKeyMap{
+	Apply: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp("enter", "apply"),
+	),
+	Recursive: key.NewBinding(
+		key.WithKeys(tea.KeyTab.String()),
+		key.WithHelp("tab", "toggle recursive"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "cancel"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "more help"),
+	),
+}
+
+type pKeyMap struct {
+	Apply     key.Binding
+	Recursive key.Binding
+	Cancel    key.Binding
+	Quit      key.Binding
+	Help      key.Binding
+}
+
+func (m pKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Apply, m.Recursive, m.Cancel, m.Help}
+}
+
+func (m pKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{m.Apply, m.Recursive},
+		{m.Cancel, m.Quit, m.Help},
+	}
+}
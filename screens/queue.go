@@ -0,0 +1,205 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/prathoss/goftp/pkg"
+)
+
+type queueModel struct {
+	overlay
+	queue  *pkg.TransferQueue
+	cursor int
+	onQuit func()
+	// draining is set when quitting was requested, the program quits once no job is running or pending
+	draining bool
+}
+
+func initQueue(queue *pkg.TransferQueue, returnTo tea.Model, onQuit func()) (tea.Model, tea.Cmd) {
+	return queueModel{
+		overlay: overlay{returnTo: returnTo},
+		queue:   queue,
+		onQuit:  onQuit,
+	}, nil
+}
+
+func initDrainingQueue(queue *pkg.TransferQueue, returnTo tea.Model, onQuit func()) (tea.Model, tea.Cmd) {
+	model, cmd := initQueue(queue, returnTo, onQuit)
+	queueModel := model.(queueModel)
+	queueModel.draining = true
+	return queueModel, cmd
+}
+
+func (m queueModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m queueModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	jobs := m.queue.Jobs()
+	m.cursor = pkg.Max(0, pkg.Min(m.cursor, len(jobs)-1))
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, qKeys.Quit):
+			m.onQuit()
+			return m, tea.Quit
+		case key.Matches(msg, qKeys.Back):
+			return m.back()
+		case key.Matches(msg, qKeys.Down):
+			if m.cursor < len(jobs)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, qKeys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, qKeys.ClearFinished):
+			m.queue.ClearFinished()
+		}
+		if len(jobs) == 0 {
+			return m, nil
+		}
+		job := jobs[m.cursor]
+		switch {
+		case key.Matches(msg, qKeys.Pause):
+			if job.State == pkg.JobPaused {
+				m.queue.Resume(job.ID)
+			} else {
+				m.queue.Pause(job.ID)
+			}
+		case key.Matches(msg, qKeys.Cancel):
+			m.queue.Cancel(job.ID)
+		case key.Matches(msg, qKeys.Retry):
+			m.queue.Retry(job.ID)
+		case key.Matches(msg, qKeys.MoveUp):
+			m.queue.Move(job.ID, -1)
+			m.cursor = pkg.Max(0, m.cursor-1)
+		case key.Matches(msg, qKeys.MoveDown):
+			m.queue.Move(job.ID, 1)
+			m.cursor = pkg.Min(len(jobs)-1, m.cursor+1)
+		}
+		return m, nil
+	}
+	cmd := m.forward(msg)
+	if m.draining && m.queue.Drained() {
+		m.onQuit()
+		return m, tea.Quit
+	}
+	return m, cmd
+}
+
+func (m queueModel) View() string {
+	jobs := m.queue.Jobs()
+	lines := make([]string, 0, len(jobs)+3)
+	if m.draining {
+		lines = append(lines, "Waiting for transfers to finish before quitting, esc to stay", "")
+	}
+	if len(jobs) == 0 {
+		lines = append(lines, "No transfers")
+	}
+	for i, job := range jobs {
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		detail := ""
+		switch job.State {
+		case pkg.JobActive:
+			detail = fmt.Sprintf("%3.0f%%", job.Progress.Percent()*100)
+		case pkg.JobFailed:
+			detail = job.Err.Error()
+		}
+		if job.Remove {
+			lines = append(lines, fmt.Sprintf("%s %-9s delete %s  %s", cursor, job.State, job.DestinationPath(), detail))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf(
+			"%s %-9s %s -> %s  %s",
+			cursor,
+			job.State,
+			job.SourcePath(),
+			job.DestinationRoot,
+			detail,
+		))
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.NewStyle().
+			Padding(0, 1).
+			Border(lipgloss.RoundedBorder(), true).
+			Render(strings.Join(lines, "\n")),
+		help.New().View(qKeys),
+	)
+}
+
+var qKeys = qKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys(tea.KeyUp.String(), "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys(tea.KeyDown.String(), "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Pause: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "pause/resume"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "cancel"),
+	),
+	Retry: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "retry"),
+	),
+	MoveUp: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "move up"),
+	),
+	MoveDown: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "move down"),
+	),
+	ClearFinished: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "clear finished"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String(), "T"),
+		key.WithHelp("esc", "back"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+}
+
+type qKeyMap struct {
+	Up            key.Binding
+	Down          key.Binding
+	Pause         key.Binding
+	Cancel        key.Binding
+	Retry         key.Binding
+	MoveUp        key.Binding
+	MoveDown      key.Binding
+	ClearFinished key.Binding
+	Back          key.Binding
+	Quit          key.Binding
+}
+
+func (q qKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{q.Up, q.Down, q.Pause, q.Cancel, q.Retry, q.MoveUp, q.MoveDown, q.ClearFinished, q.Back, q.Quit}
+}
+
+func (q qKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{q.Up, q.Down, q.Back, q.Quit},
+		{q.Pause, q.Cancel, q.Retry, q.MoveUp, q.MoveDown, q.ClearFinished},
+	}
+}
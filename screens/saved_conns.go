@@ -1,34 +1,98 @@
 package screens
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/prathoss/goftp/pkg"
 )
 
 type savedConnections struct {
 	selected int
-	confs    []pkg.ServerConf
+	// confs are ordered by group, groups in order of their first connection in the config
+	confs []pkg.ServerConf
+	// showAllHelp toggles full help
+	showAllHelp bool
+}
+
+var scGroupStyle = lipgloss.NewStyle().Bold(true)
+
+// scActionMsg changes saved connections after a dialog closed, dialogs do not change the screen they cover
+// as it is replaced with every message forwarded to it
+type scActionMsg func(s *savedConnections) error
+
+func scAction(action func(s *savedConnections) error) tea.Cmd {
+	return func() tea.Msg {
+		return scActionMsg(action)
+	}
 }
 
 func InitSavedConnections(confs []pkg.ServerConf) tea.Model {
 	return savedConnections{
 		selected: 0,
-		confs:    confs,
+		confs:    scGroup(confs),
 	}
 }
 
+// initSavedConnectionsFromConfig shows saved connections, login when there are none
+func initSavedConnectionsFromConfig() (tea.Model, tea.Cmd) {
+	cfg, err := pkg.GetConfig()
+	if err != nil {
+		model, cmd := InitLoginModel()
+		return initMessage(fmt.Sprintf("Could not read saved connections: %s", err.Error()), model, cmd)
+	}
+	if len(cfg.Servers) == 0 {
+		return InitLoginModel()
+	}
+	return InitSavedConnections(cfg.Servers), nil
+}
+
+func scGroup(confs []pkg.ServerConf) []pkg.ServerConf {
+	var groups []string
+	byGroup := make(map[string][]pkg.ServerConf)
+	for _, conf := range confs {
+		if _, ok := byGroup[conf.Group]; !ok {
+			groups = append(groups, conf.Group)
+		}
+		byGroup[conf.Group] = append(byGroup[conf.Group], conf)
+	}
+	grouped := make([]pkg.ServerConf, 0, len(confs))
+	for _, group := range groups {
+		grouped = append(grouped, byGroup[group]...)
+	}
+	return grouped
+}
+
 func (s savedConnections) Init() tea.Cmd {
 	return nil
 }
 
 func (s savedConnections) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case scActionMsg:
+		if err := msg(&s); err != nil {
+			return initMessage(fmt.Sprintf("Action failed: %s", err.Error()), s, nil)
+		}
+		return s, nil
 	case tea.KeyMsg:
+		if key.Matches(msg, scKeys.Quit) {
+			return s, tea.Quit
+		}
+		if key.Matches(msg, scKeys.New) {
+			return InitLoginModel()
+		}
+		if key.Matches(msg, scKeys.Help) {
+			s.showAllHelp = !s.showAllHelp
+			return s, nil
+		}
+		if len(s.confs) == 0 {
+			return s, nil
+		}
+		selectedServer := s.confs[s.selected]
 		switch {
 		case key.Matches(msg, scKeys.Down):
 			if s.selected < len(s.confs)-1 {
@@ -41,27 +105,142 @@ func (s savedConnections) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return s, nil
 		case key.Matches(msg, scKeys.Select):
-			selectedServer := s.confs[s.selected]
-			return InitLoginModelWithValues(selectedServer.Server, selectedServer.Port, selectedServer.User)
-		case key.Matches(msg, scKeys.Skip):
-			return InitLoginModel()
-		case key.Matches(msg, scKeys.Quit):
-			return s, tea.Quit
+			return InitLoginModelWithValues(selectedServer)
+		case key.Matches(msg, scKeys.Edit):
+			return initEditLoginModel(selectedServer)
+		case key.Matches(msg, scKeys.Delete):
+			return initConfirmation(
+				fmt.Sprintf("Really want to delete connection %s", selectedServer.Title()),
+				s,
+				nil,
+				func() tea.Cmd {
+					return scAction(func(s *savedConnections) error {
+						return s.update(selectedServer, func(cfg *pkg.Conf) error {
+							if err := cfg.RemoveServer(selectedServer); err != nil {
+								return err
+							}
+							return scForgetPassword(*cfg, selectedServer)
+						})
+					})
+				},
+				nil,
+			)
+		case key.Matches(msg, scKeys.Duplicate):
+			duplicate := selectedServer
+			duplicate.Name = fmt.Sprintf("%s (copy)", selectedServer.Title())
+			err := s.update(duplicate, func(cfg *pkg.Conf) error {
+				return cfg.InsertServerAfter(selectedServer, duplicate)
+			})
+			if err != nil {
+				return initMessage(fmt.Sprintf("Could not duplicate connection: %s", err.Error()), s, nil)
+			}
+			return s, nil
+		case key.Matches(msg, scKeys.Rename):
+			return initInput("Name of the connection, empty shows the address:", selectedServer.Name, s, nil, func(name string) tea.Cmd {
+				renamed := selectedServer
+				renamed.Name = strings.TrimSpace(name)
+				return scAction(func(s *savedConnections) error {
+					return s.update(renamed, func(cfg *pkg.Conf) error {
+						cfg.SaveServer(selectedServer, renamed)
+						return nil
+					})
+				})
+			})
+		case key.Matches(msg, scKeys.Group):
+			return initInput("Group of the connection, empty for none:", selectedServer.Group, s, nil, func(group string) tea.Cmd {
+				grouped := selectedServer
+				grouped.Group = strings.TrimSpace(group)
+				return scAction(func(s *savedConnections) error {
+					return s.update(grouped, func(cfg *pkg.Conf) error {
+						cfg.SaveServer(selectedServer, grouped)
+						return nil
+					})
+				})
+			})
+		case key.Matches(msg, scKeys.MoveUp), key.Matches(msg, scKeys.MoveDown):
+			offset := 1
+			if key.Matches(msg, scKeys.MoveUp) {
+				offset = -1
+			}
+			err := s.update(selectedServer, func(cfg *pkg.Conf) error {
+				return cfg.MoveServer(selectedServer, offset)
+			})
+			if err != nil {
+				return initMessage(fmt.Sprintf("Could not move connection: %s", err.Error()), s, nil)
+			}
+			return s, nil
 		}
 	}
 	return s, nil
 }
 
+// update changes the config file and selects the connection
+func (s *savedConnections) update(selected pkg.ServerConf, update func(cfg *pkg.Conf) error) error {
+	cfg, err := pkg.UpdateConfig(update)
+	if err != nil {
+		return err
+	}
+	s.confs = scGroup(cfg.Servers)
+	for i, conf := range s.confs {
+		if conf == selected {
+			s.selected = i
+			return nil
+		}
+	}
+	s.selected = pkg.Max(0, pkg.Min(s.selected, len(s.confs)-1))
+	return nil
+}
+
+// scForgetPassword removes remembered password of deleted connection unless other connection uses it,
+// vault is changed only when it was unlocked before
+func scForgetPassword(cfg pkg.Conf, deleted pkg.ServerConf) error {
+	for _, conf := range cfg.Servers {
+		if conf.ID() == deleted.ID() && conf.PasswordStore == deleted.PasswordStore {
+			return nil
+		}
+	}
+	var err error
+	switch deleted.PasswordStore {
+	case pkg.PasswordStoreSecretService:
+		if pkg.SecretServiceAvailable() {
+			err = pkg.SecretService{}.DeletePassword(deleted)
+		}
+	case pkg.PasswordStoreVault:
+		if vault != nil {
+			err = vault.DeletePassword(deleted)
+		}
+	}
+	if errors.Is(err, pkg.ErrNoPassword) {
+		return nil
+	}
+	return err
+}
+
 func (s savedConnections) View() string {
-	lines := make([]string, len(s.confs)+1)
+	lines := make([]string, 0, len(s.confs)+2)
 	for i, conf := range s.confs {
+		if i == 0 && conf.Group != "" || i > 0 && conf.Group != s.confs[i-1].Group {
+			group := conf.Group
+			if group == "" {
+				group = "Ungrouped"
+			}
+			lines = append(lines, scGroupStyle.Render(group))
+		}
 		selector := " "
 		if i == s.selected {
 			selector = ">"
 		}
-		lines[i] = fmt.Sprintf("%s%s@%s", selector, conf.User, conf.Server)
+		indent := ""
+		if conf.Group != "" {
+			indent = "  "
+		}
+		line := fmt.Sprintf("%s%s%s", selector, indent, conf.Title())
+		if conf.Name != "" {
+			line = fmt.Sprintf("%s (%s)", line, conf.Address())
+		}
+		lines = append(lines, line)
 	}
-	lines = append(lines, help.New().View(scKeys))
+	lines = append(lines, renderHelp(scKeys, s.showAllHelp))
 	return strings.Join(lines, "\n")
 }
 
@@ -76,30 +255,75 @@ var scKeys = scKeyMap{
 	),
 	Select: key.NewBinding(
 		key.WithKeys(tea.KeyEnter.String()),
-		key.WithHelp("enter", "select"),
+		key.WithHelp("enter", "connect"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "delete"),
 	),
-	Skip: key.NewBinding(
-		key.WithKeys("s"),
-		key.WithHelp("s", "skip"),
+	Duplicate: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "duplicate"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rename"),
+	),
+	Group: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "group"),
+	),
+	MoveUp: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "move up"),
+	),
+	MoveDown: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "move down"),
+	),
+	New: key.NewBinding(
+		key.WithKeys("n", "s"),
+		key.WithHelp("n", "new connection"),
 	),
 	Quit: key.NewBinding(
 		key.WithKeys(tea.KeyCtrlC.String(), "q"),
 		key.WithHelp("ctrl+c/q", "quit"),
 	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "more help"),
+	),
 }
 
 type scKeyMap struct {
-	Up     key.Binding
-	Down   key.Binding
-	Select key.Binding
-	Skip   key.Binding
-	Quit   key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Select    key.Binding
+	Edit      key.Binding
+	Delete    key.Binding
+	Duplicate key.Binding
+	Rename    key.Binding
+	Group     key.Binding
+	MoveUp    key.Binding
+	MoveDown  key.Binding
+	New       key.Binding
+	Quit      key.Binding
+	Help      key.Binding
 }
 
 func (s scKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{s.Up, s.Down, s.Select, s.Skip, s.Quit}
+	return []key.Binding{s.Up, s.Down, s.Select, s.New, s.Quit, s.Help}
 }
 
 func (s scKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{{s.Up, s.Down, s.Select, s.Skip, s.Quit}}
+	return [][]key.Binding{
+		{s.Up, s.Down, s.Select, s.New},
+		{s.Edit, s.Delete, s.Duplicate, s.Rename},
+		{s.Group, s.MoveUp, s.MoveDown},
+		{s.Quit, s.Help},
+	}
 }
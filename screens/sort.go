@@ -0,0 +1,160 @@
+package screens
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/prathoss/goftp/pkg"
+)
+
+// sortMenu chooses sort order of the active file list
+type sortMenu struct {
+	overlay
+	order  pkg.SortOrder
+	cursor int
+	// onApply returns command changing returnTo
+	onApply func(order pkg.SortOrder) tea.Cmd
+	onQuit  func()
+}
+
+func initSortMenu(order pkg.SortOrder, returnTo tea.Model, onApply func(order pkg.SortOrder) tea.Cmd, onQuit func()) (tea.Model, tea.Cmd) {
+	cursor := 0
+	for i, by := range pkg.SortKeys {
+		if by == order.By {
+			cursor = i
+		}
+	}
+	return sortMenu{
+		overlay: overlay{returnTo: returnTo},
+		order:   order,
+		cursor:  cursor,
+		onApply: onApply,
+		onQuit:  onQuit,
+	}, nil
+}
+
+func (m sortMenu) Init() tea.Cmd {
+	return nil
+}
+
+func (m sortMenu) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, smKeys.Quit):
+			m.onQuit()
+			return m, tea.Quit
+		case key.Matches(msg, smKeys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, smKeys.Down):
+			if m.cursor < len(pkg.SortKeys)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, smKeys.Descending):
+			m.order.Descending = !m.order.Descending
+		case key.Matches(msg, smKeys.DirsFirst):
+			m.order.Mixed = !m.order.Mixed
+		case key.Matches(msg, smKeys.Natural):
+			m.order.Natural = !m.order.Natural
+		case key.Matches(msg, smKeys.Ok):
+			m.order.By = pkg.SortKeys[m.cursor]
+			return m.backWith(m.onApply(m.order))
+		case key.Matches(msg, smKeys.Cancel):
+			return m.back()
+		}
+		return m, nil
+	}
+	cmd := m.forward(msg)
+	return m, cmd
+}
+
+func (m sortMenu) View() string {
+	lines := make([]string, 0, len(pkg.SortKeys)+4)
+	for i, by := range pkg.SortKeys {
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		lines = append(lines, fmt.Sprintf("%sby %s", cursor, by))
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("%s descending", smCheckbox(m.order.Descending)),
+		fmt.Sprintf("%s directories first", smCheckbox(!m.order.Mixed)),
+		fmt.Sprintf("%s natural order of numbers", smCheckbox(m.order.Natural)),
+	)
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.
+			NewStyle().
+			Padding(1, 3).
+			Border(lipgloss.RoundedBorder(), true).
+			Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		renderHelp(smKeys, false),
+	)
+}
+
+func smCheckbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+var smKeys = smKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys(tea.KeyUp.String(), "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys(tea.KeyDown.String(), "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Descending: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "descending"),
+	),
+	DirsFirst: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "directories first"),
+	),
+	Natural: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "natural"),
+	),
+	Ok: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp("enter", "sort"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "cancel"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+}
+
+type smKeyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	Descending key.Binding
+	DirsFirst  key.Binding
+	Natural    key.Binding
+	Ok         key.Binding
+	Cancel     key.Binding
+	Quit       key.Binding
+}
+
+func (m smKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Up, m.Down, m.Descending, m.DirsFirst, m.Natural, m.Ok, m.Cancel, m.Quit}
+}
+
+func (m smKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{m.Up, m.Down, m.Descending, m.DirsFirst, m.Natural}, {m.Ok, m.Cancel, m.Quit}}
+}
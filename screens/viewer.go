@@ -0,0 +1,579 @@
+package screens
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/prathoss/goftp/pkg"
+)
+
+const (
+	// vMaxSize caps the read part of the file, bigger files show the beginning or the end
+	vMaxSize = 1 << 20
+	// vBinaryProbe is how much of the content is checked for binary data
+	vBinaryProbe = 8 << 10
+	vHexWidth    = 16
+	vTabWidth    = 4
+	// vChromeHeight is the title and the status line, help bar is below them
+	vChromeHeight  = 2
+	vScrollColumns = 8
+)
+
+var (
+	vTitleStyle  = lipgloss.NewStyle().Bold(true)
+	vGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#909090", Dark: "#626262"})
+	vMatchStyle  = lipgloss.NewStyle().Reverse(true)
+)
+
+// viewer pages through the file, huge files are read partially
+type viewer struct {
+	overlay
+	name string
+	size uint64
+	open func(offset uint64) (io.ReadCloser, error)
+	// content is the read part, truncated is set when it is not the whole file
+	content   []byte
+	truncated bool
+	// loads counts started loads, result of older one than the last is dropped
+	loads   int
+	loading bool
+	tail    bool
+	hex     bool
+	// binary is detected when the file is read, binary files start in hex mode
+	binary      bool
+	lineNumbers bool
+	lines       []string
+	viewport    viewport.Model
+	height      int
+	xOffset     int
+	searching   bool
+	search      textinput.Model
+	matches     []int
+	match       int
+	onQuit      func()
+	// showAllHelp toggles full help
+	showAllHelp bool
+}
+
+func initViewer(name string, size uint64, open func(offset uint64) (io.ReadCloser, error), returnTo tea.Model, onQuit func()) (tea.Model, tea.Cmd) {
+	search := textinput.New()
+	search.Prompt = "/"
+	m := viewer{
+		overlay:  overlay{returnTo: returnTo},
+		name:     name,
+		size:     size,
+		open:     open,
+		search:   search,
+		onQuit:   onQuit,
+		viewport: viewport.New(0, 0),
+	}
+	m.resize(terminalSize())
+	cmd := m.load()
+	m.render()
+	return m, cmd
+}
+
+type vLoadedMsg struct {
+	load      int
+	content   []byte
+	truncated bool
+	err       error
+}
+
+// load reads the file in background
+func (m *viewer) load() tea.Cmd {
+	m.loads++
+	m.loading = true
+	load, open, size, tail := m.loads, m.open, m.size, m.tail
+	return func() tea.Msg {
+		content, truncated, err := vRead(open, size, tail)
+		return vLoadedMsg{load: load, content: content, truncated: truncated, err: err}
+	}
+}
+
+// vRead reads the beginning of the file, or the end in tail mode, at most vMaxSize bytes
+func vRead(open func(offset uint64) (io.ReadCloser, error), size uint64, tail bool) ([]byte, bool, error) {
+	var offset uint64
+	if tail && size > vMaxSize {
+		offset = size - vMaxSize
+	}
+	r, err := open(offset)
+	if err != nil {
+		return nil, false, err
+	}
+	content, readErr := io.ReadAll(io.LimitReader(r, vMaxSize+1))
+	// reading stopped before the end is reported as aborted transfer
+	closeErr := r.Close()
+	truncated := offset > 0 || len(content) > vMaxSize
+	if readErr != nil {
+		return nil, false, readErr
+	}
+	if closeErr != nil && len(content) <= vMaxSize {
+		return nil, false, closeErr
+	}
+	if len(content) > vMaxSize {
+		content = content[:vMaxSize]
+	}
+	if offset > 0 {
+		// the first line is most likely partial
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			content = content[i+1:]
+		}
+	}
+	return content, truncated, nil
+}
+
+// loaded shows the read part, binary files start in hex mode, tail mode ends at the bottom
+func (m viewer) loaded(msg vLoadedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		returnTo := tea.Model(m)
+		if msg.load == 1 {
+			// nothing was shown
+			returnTo = m.returnTo
+		}
+		return initMessageWithOnQuit(fmt.Sprintf("Could not read %s: %s", m.name, msg.err.Error()), returnTo, nil, m.onQuit)
+	}
+	m.content, m.truncated = msg.content, msg.truncated
+	m.binary = vIsBinary(m.content, m.truncated)
+	if msg.load == 1 {
+		m.hex = m.binary
+	}
+	m.render()
+	m.find()
+	if m.tail {
+		m.viewport.GotoBottom()
+	}
+	return m, nil
+}
+
+// vIsBinary looks for zero bytes and invalid utf-8, cut multibyte character at the end is ignored
+func vIsBinary(content []byte, truncated bool) bool {
+	probe := content
+	if len(probe) > vBinaryProbe {
+		probe = probe[:vBinaryProbe]
+		truncated = true
+	}
+	if bytes.IndexByte(probe, 0) >= 0 {
+		return true
+	}
+	for len(probe) > 0 {
+		r, size := utf8.DecodeRune(probe)
+		if r == utf8.RuneError && size <= 1 {
+			return !truncated || len(probe) >= utf8.UTFMax
+		}
+		probe = probe[size:]
+	}
+	return false
+}
+
+func (m *viewer) resize(width, height int) {
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = pkg.Max(height-vChromeHeight-lipgloss.Height(m.help()), 1)
+}
+
+// render splits the content into lines of the current mode and sets the visible part of them
+func (m *viewer) render() {
+	if m.hex {
+		m.lines = vHexLines(m.content)
+	} else {
+		m.lines = vTextLines(m.content)
+	}
+	m.update()
+}
+
+// update renders lines with the gutter, horizontal scroll and search highlight
+func (m *viewer) update() {
+	gutterWidth := 0
+	if m.lineNumbers && !m.hex {
+		gutterWidth = len(fmt.Sprint(len(m.lines))) + 1
+	}
+	current := -1
+	if len(m.matches) > 0 {
+		current = m.matches[m.match]
+	}
+	var b strings.Builder
+	for i, line := range m.lines {
+		if i > 0 {
+			b.WriteRune('\n')
+		}
+		if gutterWidth > 0 {
+			b.WriteString(vGutterStyle.Render(fmt.Sprintf("%*d ", gutterWidth-1, i+1)))
+		}
+		line = vCut(line, m.xOffset, m.viewport.Width-gutterWidth)
+		if i == current {
+			line = vMatchStyle.Render(line)
+		}
+		b.WriteString(line)
+	}
+	m.viewport.SetContent(b.String())
+}
+
+func vTextLines(content []byte) []string {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Map(func(r rune) rune {
+			// control characters would break the terminal
+			if r < ' ' && r != '\t' || r == 0x7f {
+				return '.'
+			}
+			return r
+		}, strings.ReplaceAll(line, "\t", strings.Repeat(" ", vTabWidth)))
+	}
+	return lines
+}
+
+func vHexLines(content []byte) []string {
+	lines := make([]string, 0, len(content)/vHexWidth+1)
+	for offset := 0; offset < len(content); offset += vHexWidth {
+		chunk := content[offset:pkg.Min(offset+vHexWidth, len(content))]
+		var hex, ascii strings.Builder
+		for i := 0; i < vHexWidth; i++ {
+			if i == vHexWidth/2 {
+				hex.WriteRune(' ')
+			}
+			if i >= len(chunk) {
+				hex.WriteString("   ")
+				continue
+			}
+			hex.WriteString(fmt.Sprintf("%02x ", chunk[i]))
+			if chunk[i] >= ' ' && chunk[i] < 0x7f {
+				ascii.WriteByte(chunk[i])
+			} else {
+				ascii.WriteRune('.')
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%08x  %s |%s|", offset, hex.String(), ascii.String()))
+	}
+	return lines
+}
+
+// vCut returns width of terminal cells of line starting at offset cell
+func vCut(line string, offset, width int) string {
+	if offset > 0 {
+		skipped := 0
+		for i, r := range line {
+			if skipped >= offset {
+				line = line[i:]
+				break
+			}
+			skipped += runewidth.RuneWidth(r)
+		}
+		if skipped < offset {
+			return ""
+		}
+	}
+	return runewidth.Truncate(line, width, "")
+}
+
+// find collects lines containing the search ignoring case and moves to the first one from the top of the view
+func (m *viewer) find() {
+	m.matches = nil
+	m.match = 0
+	query := strings.ToLower(m.search.Value())
+	if query == "" {
+		m.update()
+		return
+	}
+	for i, line := range m.lines {
+		if strings.Contains(strings.ToLower(line), query) {
+			if len(m.matches) > 0 && m.matches[m.match] < m.viewport.YOffset && i >= m.viewport.YOffset {
+				m.match = len(m.matches)
+			}
+			m.matches = append(m.matches, i)
+		}
+	}
+	m.showMatch()
+}
+
+func (m *viewer) showMatch() {
+	m.update()
+	if len(m.matches) == 0 {
+		return
+	}
+	line := m.matches[m.match]
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height/2)
+	}
+}
+
+func (m viewer) Init() tea.Cmd {
+	return nil
+}
+
+func (m viewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, vKeys.Quit) {
+			if m.onQuit != nil {
+				m.onQuit()
+			}
+			return m, tea.Quit
+		}
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		switch {
+		case key.Matches(msg, vKeys.Back):
+			return m.back()
+		case key.Matches(msg, vKeys.Help):
+			m.showAllHelp = !m.showAllHelp
+			m.resize(m.viewport.Width, m.height)
+		case key.Matches(msg, vKeys.Top):
+			m.viewport.GotoTop()
+		case key.Matches(msg, vKeys.Bottom):
+			m.viewport.GotoBottom()
+		case key.Matches(msg, vKeys.Left):
+			m.xOffset = pkg.Max(0, m.xOffset-vScrollColumns)
+			m.update()
+		case key.Matches(msg, vKeys.Right):
+			m.xOffset += vScrollColumns
+			m.update()
+		case key.Matches(msg, vKeys.Search):
+			m.searching = true
+			m.search.SetValue("")
+			return m, m.search.Focus()
+		case key.Matches(msg, vKeys.NextMatch):
+			if len(m.matches) > 0 {
+				m.match = (m.match + 1) % len(m.matches)
+				m.showMatch()
+			}
+		case key.Matches(msg, vKeys.PreviousMatch):
+			if len(m.matches) > 0 {
+				m.match = (m.match + len(m.matches) - 1) % len(m.matches)
+				m.showMatch()
+			}
+		case key.Matches(msg, vKeys.LineNumbers):
+			m.lineNumbers = !m.lineNumbers
+			m.update()
+		case key.Matches(msg, vKeys.Hex):
+			m.hex = !m.hex
+			m.xOffset = 0
+			m.viewport.GotoTop()
+			m.render()
+			m.find()
+		case key.Matches(msg, vKeys.Tail), key.Matches(msg, vKeys.Reload):
+			if key.Matches(msg, vKeys.Tail) {
+				m.tail = !m.tail
+			}
+			return m.reload()
+		default:
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	case vLoadedMsg:
+		if msg.load != m.loads {
+			return m, nil
+		}
+		return m.loaded(msg)
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+		m.update()
+	}
+	if m.searching {
+		var cmd tea.Cmd
+		m.search, cmd = m.search.Update(msg)
+		return m, tea.Batch(cmd, m.forward(msg))
+	}
+	cmd := m.forward(msg)
+	return m, cmd
+}
+
+func (m viewer) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, vsKeys.Find):
+		m.searching = false
+		m.search.Blur()
+		m.find()
+		return m, nil
+	case key.Matches(msg, vsKeys.Cancel):
+		m.searching = false
+		m.search.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	return m, cmd
+}
+
+// reload reads the file again
+func (m viewer) reload() (tea.Model, tea.Cmd) {
+	cmd := m.load()
+	return m, cmd
+}
+
+func (m viewer) View() string {
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		vTitleStyle.Render(pkg.Ellipsize(m.name, m.viewport.Width)),
+		m.viewport.View(),
+		m.status(),
+	)
+}
+
+func (m viewer) status() string {
+	if m.searching {
+		return lipgloss.JoinHorizontal(lipgloss.Top, m.search.View(), "  ", fHelp(m.viewport.Width).View(vsKeys))
+	}
+	parts := []string{fmt.Sprintf("%3.f%%", m.viewport.ScrollPercent()*100), pkg.PrettyPrintSize(m.size)}
+	if m.loading {
+		parts = append(parts, "loading")
+	}
+	if m.truncated {
+		part := "beginning"
+		if m.tail {
+			part = "end"
+		}
+		parts = append(parts, fmt.Sprintf("showing %s of the file", part))
+	}
+	if m.binary {
+		parts = append(parts, "binary")
+	}
+	if m.search.Value() != "" {
+		if len(m.matches) == 0 {
+			parts = append(parts, fmt.Sprintf("%q not found", m.search.Value()))
+		} else {
+			parts = append(parts, fmt.Sprintf("match %d/%d", m.match+1, len(m.matches)))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, vGutterStyle.Render(strings.Join(parts, " • ")), m.help())
+}
+
+func (m viewer) help() string {
+	h := fHelp(m.viewport.Width)
+	h.ShowAll = m.showAllHelp
+	return h.View(vKeys)
+}
+
+var vKeys = vKeyMap{
+	Scroll: key.NewBinding(
+		key.WithKeys(tea.KeyUp.String(), "k", tea.KeyDown.String(), "j", tea.KeyPgUp.String(), tea.KeyPgDown.String()),
+		key.WithHelp("↑/k/↓/j/pgup/pgdown", "scroll"),
+	),
+	Left: key.NewBinding(
+		key.WithKeys(tea.KeyLeft.String(), "h"),
+		key.WithHelp("←/h", "left"),
+	),
+	Right: key.NewBinding(
+		key.WithKeys(tea.KeyRight.String(), "l"),
+		key.WithHelp("→/l", "right"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("g", tea.KeyHome.String()),
+		key.WithHelp("g/home", "top"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("G", tea.KeyEnd.String()),
+		key.WithHelp("G/end", "bottom"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	PreviousMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous match"),
+	),
+	LineNumbers: key.NewBinding(
+		key.WithKeys("#"),
+		key.WithHelp("#", "line numbers"),
+	),
+	Hex: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "hex"),
+	),
+	Tail: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "beginning/end of big file"),
+	),
+	Reload: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reload"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String(), "q"),
+		key.WithHelp("esc/q", "close"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys(tea.KeyCtrlC.String()),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "more help"),
+	),
+}
+
+type vKeyMap struct {
+	Scroll        key.Binding
+	Left          key.Binding
+	Right         key.Binding
+	Top           key.Binding
+	Bottom        key.Binding
+	Search        key.Binding
+	NextMatch     key.Binding
+	PreviousMatch key.Binding
+	LineNumbers   key.Binding
+	Hex           key.Binding
+	Tail          key.Binding
+	Reload        key.Binding
+	Back          key.Binding
+	Quit          key.Binding
+	Help          key.Binding
+}
+
+func (m vKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Scroll, m.Search, m.Hex, m.Back, m.Help}
+}
+
+func (m vKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{m.Scroll, m.Left, m.Right, m.Top, m.Bottom},
+		{m.Search, m.NextMatch, m.PreviousMatch},
+		{m.LineNumbers, m.Hex, m.Tail, m.Reload},
+		{m.Back, m.Quit, m.Help},
+	}
+}
+
+// vsKeys are used while the search is typed
+var vsKeys = vsKeyMap{
+	Find: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp("enter", "find"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+type vsKeyMap struct {
+	Find   key.Binding
+	Cancel key.Binding
+}
+
+func (m vsKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{m.Find, m.Cancel}
+}
+
+func (m vsKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{m.Find, m.Cancel}}
+}
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"io/fs"
+	"time"
+)
+
 const (
 	TypeDirectory = iota
 	TypeFile
@@ -10,6 +15,23 @@ type Entry struct {
 	Name string
 	Type int
 	Size uint64
+	// ModTime may be zero or imprecise when the server does not report it
+	ModTime time.Time
+	// Mode holds permission bits, it is zero when the server does not report them
+	Mode fs.FileMode
+	// Owner and Group are names or ids, empty when unknown
+	Owner string
+	Group string
+	// LinkTarget is where symbolic link points to, empty when unknown
+	LinkTarget string
+}
+
+// Permissions are in form of ls, empty when unknown
+func (e Entry) Permissions() string {
+	if e.Mode == 0 {
+		return ""
+	}
+	return e.TypeString() + e.Mode.Perm().String()[1:]
 }
 
 func (e Entry) TypeString() string {